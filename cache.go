@@ -0,0 +1,61 @@
+package promptgen
+
+import (
+	"context"
+
+	"github.com/arjunsriva/promptgen/cache"
+	"github.com/arjunsriva/promptgen/provider"
+)
+
+// WithCache stores validated responses in c and answers repeated calls with
+// the same final prompt, parameters and provider from it. Responses that fail
+// parsing or validation are never stored. A cache that fails to read or
+// write is skipped so that it never fails a call. Streams are not cached.
+func (g *Generator[I, O]) WithCache(c cache.Cache) *Generator[I, O] {
+	g.cache = c
+	return g
+}
+
+// cacheKey returns the cache key for req, or "" when caching is disabled
+func (g *Generator[I, O]) cacheKey(req provider.Request) string {
+	if g.cache == nil {
+		return ""
+	}
+	// Key on the parameters the provider will actually use, so that
+	// providers configured with different models keep their answers apart
+	req.Params = provider.DefaultsOf(g.provider).Merge(req.Params)
+	return cache.Key(provider.NameOf(g.provider), req)
+}
+
+// cached fills result from the cache. A cached response that no longer
+// passes validation, e.g. after the output type changed, counts as a miss.
+func (g *Generator[I, O]) cached(ctx context.Context, key string, result *Result[O]) bool {
+	if key == "" {
+		return false
+	}
+	response, ok, err := g.cache.Get(ctx, key)
+	if err != nil || !ok {
+		return false
+	}
+
+	output, perr := g.process(response.Content)
+	if perr != nil {
+		return false
+	}
+
+	// Nothing was spent on this call, so only the response's origin is kept
+	result.Output = output
+	result.Model = response.Model
+	result.Provider = response.Provider
+	result.FinishReason = response.FinishReason
+	result.CacheHit = true
+	return true
+}
+
+// store saves a validated response in the cache
+func (g *Generator[I, O]) store(ctx context.Context, key string, response provider.Response) {
+	if key == "" {
+		return
+	}
+	g.cache.Set(ctx, key, response)
+}
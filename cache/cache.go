@@ -0,0 +1,33 @@
+// Package cache stores validated provider responses so that identical
+// prompts are not sent to the provider twice.
+//
+// Use it with Generator.WithCache:
+//
+//	generator.WithCache(cache.NewMemory(1000, time.Hour))
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/arjunsriva/promptgen/provider"
+)
+
+// Cache is a response store. Implementations must be safe for concurrent use.
+type Cache interface {
+	// Get returns the response stored under key, if any
+	Get(ctx context.Context, key string) (provider.Response, bool, error)
+	// Set stores resp under key
+	Set(ctx context.Context, key string, resp provider.Response) error
+}
+
+// Key derives a cache key from the final request, covering the wrapped
+// messages, generation parameters, response schema and tools, and from the
+// name of the provider it is sent to. Pass the parameters the provider will
+// actually use, merged with its defaults, so that responses from different
+// models stay apart.
+func Key(providerName string, req provider.Request) string {
+	sum := sha256.Sum256([]byte(providerName + "\n" + req.Hash()))
+	return hex.EncodeToString(sum[:])
+}
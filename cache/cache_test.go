@@ -0,0 +1,36 @@
+package cache
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/arjunsriva/promptgen/provider"
+)
+
+func TestKey(t *testing.T) {
+	base := provider.NewRequest("Classify: great product")
+	key := Key("openai", base)
+
+	if Key("openai", provider.NewRequest("Classify: great product")) != key {
+		t.Error("identical requests should have the same key")
+	}
+
+	tests := map[string]struct {
+		provider string
+		req      provider.Request
+	}{
+		"prompt":   {"openai", provider.NewRequest("Classify: bad product")},
+		"provider": {"anthropic", base},
+		"model":    {"openai", provider.Request{Messages: base.Messages, Params: provider.Params{Model: "gpt-4o"}}},
+		"params":   {"openai", provider.Request{Messages: base.Messages, Params: provider.Params{Temperature: provider.Float64(0)}}},
+		"schema":   {"openai", provider.Request{Messages: base.Messages, Schema: json.RawMessage(`{"type":"object"}`)}},
+		"tools":    {"openai", provider.Request{Messages: base.Messages, Tools: []provider.Tool{{Name: "search"}}}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if Key(tt.provider, tt.req) == key {
+				t.Errorf("changing the %s should change the key", name)
+			}
+		})
+	}
+}
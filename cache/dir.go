@@ -0,0 +1,90 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/arjunsriva/promptgen/provider"
+)
+
+// Dir is a cache that stores each response as a JSON file in a directory,
+// so that it survives restarts and can be shared between processes
+type Dir struct {
+	path string
+	ttl  time.Duration
+
+	now func() time.Time
+}
+
+type dirEntry struct {
+	Created  time.Time         `json:"created"`
+	Response provider.Response `json:"response"`
+}
+
+// NewDir creates a cache in the directory at path, creating it if needed.
+// Responses older than ttl are ignored; a ttl of zero keeps them forever.
+func NewDir(path string, ttl time.Duration) (*Dir, error) {
+	if err := os.MkdirAll(path, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	return &Dir{path: path, ttl: ttl, now: time.Now}, nil
+}
+
+// Get returns the response stored under key unless it has expired
+func (d *Dir) Get(_ context.Context, key string) (provider.Response, bool, error) {
+	data, err := os.ReadFile(d.file(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return provider.Response{}, false, nil
+	}
+	if err != nil {
+		return provider.Response{}, false, fmt.Errorf("failed to read cache entry: %w", err)
+	}
+
+	var entry dirEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return provider.Response{}, false, fmt.Errorf("failed to decode cache entry: %w", err)
+	}
+	if d.ttl > 0 && d.now().Sub(entry.Created) >= d.ttl {
+		os.Remove(d.file(key))
+		return provider.Response{}, false, nil
+	}
+	return entry.Response, true, nil
+}
+
+// Set stores resp under key. The file is written in full before it replaces
+// any existing entry, so concurrent readers never see a partial entry.
+func (d *Dir) Set(_ context.Context, key string, resp provider.Response) error {
+	data, err := json.Marshal(dirEntry{Created: d.now(), Response: resp})
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(d.path, key+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), d.file(key)); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return nil
+}
+
+// file returns the path of the entry for key
+func (d *Dir) file(key string) string {
+	return filepath.Join(d.path, filepath.Base(key)+".json")
+}
@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/arjunsriva/promptgen/provider"
+)
+
+func TestDir(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "responses")
+
+	d, err := NewDir(path, 0)
+	if err != nil {
+		t.Fatalf("NewDir() error = %v", err)
+	}
+	if _, ok, err := d.Get(ctx, "a"); ok || err != nil {
+		t.Errorf("Get() on an empty cache = %v, %v", ok, err)
+	}
+
+	want := provider.Response{
+		Content:  `{"label": "positive"}`,
+		Model:    "gpt-4o",
+		Provider: "openai",
+		Usage:    provider.Usage{TotalTokens: 12},
+	}
+	if err := d.Set(ctx, "a", want); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	// Entries survive across instances
+	reopened, _ := NewDir(path, 0)
+	got, ok, err := reopened.Get(ctx, "a")
+	if err != nil || !ok {
+		t.Fatalf("Get() = %v, %v", ok, err)
+	}
+	if got.Content != want.Content || got.Model != want.Model || got.Usage != want.Usage {
+		t.Errorf("Get() = %+v, want %+v", got, want)
+	}
+
+	files, _ := os.ReadDir(path)
+	if len(files) != 1 {
+		t.Errorf("expected only the entry to remain, got %d files", len(files))
+	}
+}
+
+func TestDirTTL(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	d, _ := NewDir(t.TempDir(), time.Hour)
+	d.now = func() time.Time { return now }
+
+	d.Set(ctx, "a", provider.Response{Content: "A"})
+	now = now.Add(time.Hour)
+	if _, ok, _ := d.Get(ctx, "a"); ok {
+		t.Error("expected a miss after the TTL")
+	}
+}
+
+func TestDirCorrupt(t *testing.T) {
+	path := t.TempDir()
+	d, _ := NewDir(path, 0)
+	os.WriteFile(filepath.Join(path, "a.json"), []byte("{"), 0o644)
+
+	if _, ok, err := d.Get(context.Background(), "a"); ok || err == nil {
+		t.Errorf("Get() = %v, %v, want an error", ok, err)
+	}
+}
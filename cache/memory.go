@@ -0,0 +1,93 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/arjunsriva/promptgen/provider"
+)
+
+// Memory is an in-memory cache that evicts the least recently used entry
+// once it is full and drops entries older than its TTL
+type Memory struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	entries map[string]*list.Element
+	order   *list.List // most recently used first
+
+	now func() time.Time
+}
+
+type memoryEntry struct {
+	key      string
+	response provider.Response
+	expires  time.Time
+}
+
+// NewMemory creates an in-memory cache holding up to size responses for up
+// to ttl each. A size or ttl of zero means no limit.
+func NewMemory(size int, ttl time.Duration) *Memory {
+	return &Memory{
+		size:    size,
+		ttl:     ttl,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+		now:     time.Now,
+	}
+}
+
+// Get returns the response stored under key unless it has expired
+func (m *Memory) Get(_ context.Context, key string) (provider.Response, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	elem, ok := m.entries[key]
+	if !ok {
+		return provider.Response{}, false, nil
+	}
+	entry := elem.Value.(*memoryEntry)
+	if m.ttl > 0 && !m.now().Before(entry.expires) {
+		m.remove(elem)
+		return provider.Response{}, false, nil
+	}
+
+	m.order.MoveToFront(elem)
+	return entry.response, true, nil
+}
+
+// Set stores resp under key, evicting the least recently used entry if the
+// cache is full
+func (m *Memory) Set(_ context.Context, key string, resp provider.Response) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry := &memoryEntry{key: key, response: resp, expires: m.now().Add(m.ttl)}
+	if elem, ok := m.entries[key]; ok {
+		elem.Value = entry
+		m.order.MoveToFront(elem)
+		return nil
+	}
+
+	m.entries[key] = m.order.PushFront(entry)
+	if m.size > 0 && m.order.Len() > m.size {
+		m.remove(m.order.Back())
+	}
+	return nil
+}
+
+// Len returns the number of stored responses, including expired ones that
+// have not been evicted yet
+func (m *Memory) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.order.Len()
+}
+
+// remove deletes an entry. Callers must hold mu.
+func (m *Memory) remove(elem *list.Element) {
+	m.order.Remove(elem)
+	delete(m.entries, elem.Value.(*memoryEntry).key)
+}
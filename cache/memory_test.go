@@ -0,0 +1,87 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/arjunsriva/promptgen/provider"
+)
+
+func TestMemory(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory(2, 0)
+
+	if _, ok, _ := m.Get(ctx, "a"); ok {
+		t.Error("expected a miss on an empty cache")
+	}
+
+	m.Set(ctx, "a", provider.Response{Content: "A"})
+	m.Set(ctx, "b", provider.Response{Content: "B"})
+	if resp, ok, _ := m.Get(ctx, "a"); !ok || resp.Content != "A" {
+		t.Errorf("Get(a) = %+v, %v", resp, ok)
+	}
+
+	// b is now the least recently used entry
+	m.Set(ctx, "c", provider.Response{Content: "C"})
+	if _, ok, _ := m.Get(ctx, "b"); ok {
+		t.Error("expected b to be evicted")
+	}
+	if _, ok, _ := m.Get(ctx, "a"); !ok {
+		t.Error("expected a to be kept")
+	}
+	if m.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", m.Len())
+	}
+
+	m.Set(ctx, "a", provider.Response{Content: "A2"})
+	if resp, _, _ := m.Get(ctx, "a"); resp.Content != "A2" {
+		t.Errorf("expected a to be replaced, got %q", resp.Content)
+	}
+}
+
+func TestMemoryTTL(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := NewMemory(0, time.Minute)
+	m.now = func() time.Time { return now }
+
+	m.Set(ctx, "a", provider.Response{Content: "A"})
+	now = now.Add(59 * time.Second)
+	if _, ok, _ := m.Get(ctx, "a"); !ok {
+		t.Error("expected a hit before the TTL")
+	}
+
+	now = now.Add(time.Second)
+	if _, ok, _ := m.Get(ctx, "a"); ok {
+		t.Error("expected a miss after the TTL")
+	}
+	if m.Len() != 0 {
+		t.Errorf("expired entries should be removed, Len() = %d", m.Len())
+	}
+}
+
+func TestMemoryConcurrent(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory(10, time.Minute)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			key := fmt.Sprint(i % 15)
+			m.Set(ctx, key, provider.Response{Content: key})
+			if resp, ok, _ := m.Get(ctx, key); ok && resp.Content != key {
+				t.Errorf("Get(%s) = %q", key, resp.Content)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if m.Len() > 10 {
+		t.Errorf("Len() = %d, want at most 10", m.Len())
+	}
+}
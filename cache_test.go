@@ -0,0 +1,143 @@
+package promptgen
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/arjunsriva/promptgen/cache"
+	"github.com/arjunsriva/promptgen/provider"
+)
+
+// failingCache is a cache whose backend is unavailable
+type failingCache struct{}
+
+func (failingCache) Get(context.Context, string) (provider.Response, bool, error) {
+	return provider.Response{}, false, errors.New("disk full")
+}
+
+func (failingCache) Set(context.Context, string, provider.Response) error {
+	return errors.New("disk full")
+}
+
+// modelMock is a mock provider configured with a default model
+type modelMock struct {
+	*provider.MockProvider
+	model string
+}
+
+func (m modelMock) Defaults() provider.Params {
+	return provider.Params{Model: m.model}
+}
+
+func TestCache(t *testing.T) {
+	t.Run("hit", func(t *testing.T) {
+		mock := &provider.MockProvider{
+			Response: `{"response": "Hello"}`,
+			Usage:    provider.Usage{TotalTokens: 15},
+		}
+		gen, _ := Create[TestInput, TestOutput]("Hello {{.Message}}")
+		gen.WithProvider(mock).WithCache(cache.NewMemory(10, 0))
+
+		first, err := gen.RunWithMeta(context.Background(), TestInput{Message: "test"})
+		if err != nil || first.CacheHit {
+			t.Fatalf("first call = %+v, %v", first, err)
+		}
+
+		second, err := gen.RunWithMeta(context.Background(), TestInput{Message: "test"})
+		if err != nil {
+			t.Fatalf("RunWithMeta() error = %v", err)
+		}
+		if !second.CacheHit || second.Output.Response != "Hello" || second.Provider != "mock" {
+			t.Errorf("expected a cache hit, got %+v", second)
+		}
+		if second.Usage.TotalTokens != 0 {
+			t.Errorf("a cache hit should use no tokens, got %+v", second.Usage)
+		}
+		if len(mock.Requests) != 1 {
+			t.Errorf("expected 1 request, got %d", len(mock.Requests))
+		}
+
+		if _, err := gen.Run(context.Background(), TestInput{Message: "other"}); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		if len(mock.Requests) != 2 {
+			t.Errorf("a different prompt should miss the cache")
+		}
+	})
+
+	t.Run("parameters are part of the key", func(t *testing.T) {
+		mock := &provider.MockProvider{Response: `{"response": "Hello"}`}
+		gen, _ := Create[TestInput, TestOutput]("Hello {{.Message}}")
+		gen.WithProvider(mock).WithCache(cache.NewMemory(10, 0))
+
+		gen.Run(context.Background(), TestInput{Message: "test"})
+		gen.Run(ContextWithParams(context.Background(), Params{Model: "gpt-4o"}), TestInput{Message: "test"})
+		if len(mock.Requests) != 2 {
+			t.Errorf("expected 2 requests, got %d", len(mock.Requests))
+		}
+	})
+
+	t.Run("provider defaults are part of the key", func(t *testing.T) {
+		shared := cache.NewMemory(10, 0)
+		run := func(model string) *provider.MockProvider {
+			mock := &provider.MockProvider{Response: `{"response": "Hello"}`}
+			gen, _ := Create[TestInput, TestOutput]("Hello {{.Message}}")
+			gen.WithProvider(modelMock{mock, model}).WithCache(shared)
+			if _, err := gen.Run(context.Background(), TestInput{Message: "test"}); err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			return mock
+		}
+
+		run("gpt-4o")
+		if mock := run("gpt-4o-mini"); len(mock.Requests) != 1 {
+			t.Error("a provider configured with another model should miss the cache")
+		}
+		if mock := run("gpt-4o"); len(mock.Requests) != 0 {
+			t.Error("a provider configured with the same model should hit the cache")
+		}
+	})
+
+	t.Run("invalid responses are not stored", func(t *testing.T) {
+		mock := &provider.MockProvider{Response: `{"response": 1}`}
+		c := cache.NewMemory(10, 0)
+		gen, _ := Create[TestInput, TestOutput]("Hello {{.Message}}")
+		gen.WithProvider(mock).WithCache(c)
+
+		if _, err := gen.Run(context.Background(), TestInput{Message: "test"}); err == nil {
+			t.Fatal("expected a parse error")
+		}
+		if c.Len() != 0 {
+			t.Errorf("expected an empty cache, got %d entries", c.Len())
+		}
+	})
+
+	t.Run("repaired responses are stored", func(t *testing.T) {
+		mock := &provider.MockProvider{
+			Response:  `{"response": "Hello"}`,
+			Responses: []provider.Response{{Content: `{"response": 1}`}},
+		}
+		gen, _ := Create[TestInput, TestOutput]("Hello {{.Message}}")
+		gen.WithProvider(mock).WithCache(cache.NewMemory(10, 0)).WithRepair(1)
+
+		gen.Run(context.Background(), TestInput{Message: "test"})
+		result, err := gen.RunWithMeta(context.Background(), TestInput{Message: "test"})
+		if err != nil || !result.CacheHit {
+			t.Errorf("expected a cache hit, got %+v, %v", result, err)
+		}
+		if len(mock.Requests) != 2 {
+			t.Errorf("expected 2 requests, got %d", len(mock.Requests))
+		}
+	})
+
+	t.Run("failing cache", func(t *testing.T) {
+		mock := &provider.MockProvider{Response: `{"response": "Hello"}`}
+		gen, _ := Create[TestInput, TestOutput]("Hello {{.Message}}")
+		gen.WithProvider(mock).WithCache(failingCache{})
+
+		if output, err := gen.Run(context.Background(), TestInput{Message: "test"}); err != nil || output.Response != "Hello" {
+			t.Errorf("Run() = %+v, %v", output, err)
+		}
+	})
+}
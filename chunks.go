@@ -0,0 +1,44 @@
+package promptgen
+
+import (
+	"context"
+	"iter"
+)
+
+// Chunks streams the generated content as an iterator:
+//
+//	for chunk, err := range generator.Chunks(ctx, input) {
+//	    if err != nil {
+//	        return err
+//	    }
+//	    fmt.Print(chunk)
+//	}
+//
+// A failed stream yields a single error as its last element. Breaking out of
+// the loop cancels the underlying request and releases its resources.
+func (g *Generator[I, O]) Chunks(ctx context.Context, input I) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		stream, err := g.Stream(ctx, input)
+		if err != nil {
+			yield("", providerError(ctx, err))
+			return
+		}
+
+		for chunk := range stream.Content {
+			if !yield(chunk, nil) {
+				// Stop the request and wait for the stream to shut down
+				cancel()
+				for range stream.Content {
+				}
+				return
+			}
+		}
+
+		if err := <-stream.Err; err != nil {
+			yield("", providerError(ctx, err))
+		}
+	}
+}
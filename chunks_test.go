@@ -0,0 +1,161 @@
+package promptgen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/arjunsriva/promptgen/provider"
+)
+
+// endlessProvider streams tokens until its context is cancelled
+type endlessProvider struct {
+	stopped chan struct{}
+}
+
+func (p *endlessProvider) Complete(_ context.Context, _ provider.Request) (provider.Response, error) {
+	return provider.Response{}, errors.New("not implemented")
+}
+
+func (p *endlessProvider) Stream(ctx context.Context, _ provider.Request) (<-chan string, <-chan error, error) {
+	content := make(chan string)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(p.stopped)
+		defer close(content)
+		defer close(errs)
+
+		for {
+			select {
+			case content <- "token":
+			case <-ctx.Done():
+				errs <- ctx.Err()
+				return
+			}
+		}
+	}()
+
+	return content, errs, nil
+}
+
+// unavailableProvider fails every stream before it starts
+type unavailableProvider struct {
+	err error
+}
+
+func (p *unavailableProvider) Complete(_ context.Context, _ provider.Request) (provider.Response, error) {
+	return provider.Response{}, p.err
+}
+
+func (p *unavailableProvider) Stream(_ context.Context, _ provider.Request) (<-chan string, <-chan error, error) {
+	return nil, nil, p.err
+}
+
+func TestChunks(t *testing.T) {
+	t.Run("yields all chunks", func(t *testing.T) {
+		mock := &provider.MockProvider{StreamTokens: []string{"Hello", " ", "world"}}
+
+		gen, _ := Create[string, string]("test")
+		gen.WithProvider(mock)
+
+		var chunks []string
+		for chunk, err := range gen.Chunks(context.Background(), "test") {
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			chunks = append(chunks, chunk)
+		}
+
+		want := []string{"Hello", " ", "world"}
+		if !reflect.DeepEqual(chunks, want) {
+			t.Errorf("Chunks() = %v, want %v", chunks, want)
+		}
+	})
+
+	t.Run("yields provider error", func(t *testing.T) {
+		mock := &provider.MockProvider{Errors: []error{provider.ErrRateLimit}}
+
+		gen, _ := Create[string, string]("test")
+		gen.WithProvider(mock)
+
+		var errs []error
+		for chunk, err := range gen.Chunks(context.Background(), "test") {
+			if err == nil {
+				t.Errorf("unexpected chunk %q", chunk)
+				continue
+			}
+			errs = append(errs, err)
+		}
+
+		if len(errs) != 1 || !errors.Is(errs[0], ErrRateLimit) {
+			t.Errorf("expected a single rate limit error, got %v", errs)
+		}
+	})
+
+	t.Run("maps stream setup errors", func(t *testing.T) {
+		gen, _ := Create[string, string]("test")
+		gen.WithProvider(&unavailableProvider{err: fmt.Errorf("anthropic: %w", provider.ErrRateLimit)})
+
+		var errs []error
+		for _, err := range gen.Chunks(context.Background(), "test") {
+			errs = append(errs, err)
+		}
+		if len(errs) != 1 || !IsRateLimit(errs[0]) {
+			t.Errorf("expected a single rate limit error, got %v", errs)
+		}
+	})
+
+	t.Run("early break cancels the stream", func(t *testing.T) {
+		p := &endlessProvider{stopped: make(chan struct{})}
+
+		gen, _ := Create[string, string]("test")
+		gen.WithProvider(p)
+
+		count := 0
+		for _, err := range gen.Chunks(context.Background(), "test") {
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			count++
+			if count == 3 {
+				break
+			}
+		}
+
+		select {
+		case <-p.stopped:
+		case <-time.After(time.Second):
+			t.Fatal("provider stream was not cancelled after break")
+		}
+		if count != 3 {
+			t.Errorf("expected 3 chunks, got %d", count)
+		}
+	})
+
+	t.Run("parent context cancellation", func(t *testing.T) {
+		p := &endlessProvider{stopped: make(chan struct{})}
+
+		gen, _ := Create[string, string]("test")
+		gen.WithProvider(p)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var lastErr error
+		for _, err := range gen.Chunks(ctx, "test") {
+			if err != nil {
+				lastErr = err
+				break
+			}
+			cancel()
+		}
+
+		if !errors.Is(lastErr, context.Canceled) {
+			t.Errorf("expected cancellation error, got %v", lastErr)
+		}
+	})
+}
@@ -3,6 +3,8 @@ package promptgen
 import (
 	"errors"
 	"fmt"
+
+	"github.com/arjunsriva/promptgen/schema"
 )
 
 // Common error types for the promptgen package
@@ -14,8 +16,23 @@ var (
 	ErrTimeout         = errors.New("request timeout")
 	ErrRateLimit       = errors.New("rate limit exceeded")
 	ErrContextLength   = errors.New("context length exceeded")
+	ErrCircuitOpen     = errors.New("circuit breaker open")
 )
 
+// ValidationError lists each schema violation of an output that failed
+// validation. Use errors.As to get it from the error returned by Run:
+//
+//	var verr *promptgen.ValidationError
+//	if errors.As(err, &verr) {
+//	    for _, v := range verr.Violations {
+//	        log.Printf("%s broke %s", v.Path, v.Rule)
+//	    }
+//	}
+type ValidationError = schema.ValidationError
+
+// Violation is a single schema rule broken by an output
+type Violation = schema.Violation
+
 // Error wraps provider errors with additional context
 type Error struct {
 	Err     error
@@ -51,3 +68,8 @@ func IsRateLimit(err error) bool {
 func IsContextLength(err error) bool {
 	return errors.Is(err, ErrContextLength)
 }
+
+// IsCircuitOpen checks if the error comes from an open circuit breaker
+func IsCircuitOpen(err error) bool {
+	return errors.Is(err, ErrCircuitOpen)
+}
@@ -1,9 +1,12 @@
 package promptgen
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"testing"
+
+	"github.com/arjunsriva/promptgen/provider"
 )
 
 func TestError(t *testing.T) {
@@ -83,6 +86,18 @@ func TestErrorPredicates(t *testing.T) {
 			check:    IsContextLength,
 			expected: false,
 		},
+		{
+			name:     "IsCircuitOpen with circuit open error",
+			err:      &Error{Err: ErrCircuitOpen},
+			check:    IsCircuitOpen,
+			expected: true,
+		},
+		{
+			name:     "IsCircuitOpen with other error",
+			err:      &Error{Err: ErrRateLimit},
+			check:    IsCircuitOpen,
+			expected: false,
+		},
 		{
 			name:     "nil error",
 			err:      nil,
@@ -121,7 +136,51 @@ func getFunctionName(f func(error) bool) string {
 		return "IsRateLimit"
 	case f(testErr) == IsContextLength(testErr):
 		return "IsContextLength"
+	case f(testErr) == IsCircuitOpen(testErr):
+		return "IsCircuitOpen"
 	default:
 		return "unknown"
 	}
 }
+
+func TestValidationErrorFromRun(t *testing.T) {
+	type Review struct {
+		Summary   string `json:"summary" jsonschema:"required,maxLength=20"`
+		Sentiment string `json:"sentiment" jsonschema:"required,enum=positive,enum=negative"`
+	}
+
+	mock := &provider.MockProvider{Response: `{"summary": "A very long summary of the review", "sentiment": "mixed"}`}
+	gen, _ := Create[string, Review]("Summarize: {{.}}")
+	gen.WithProvider(mock)
+
+	_, err := gen.Run(context.Background(), "Great chair")
+	if !IsValidation(err) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected a *ValidationError, got %v", err)
+	}
+	rules := map[string]string{}
+	for _, v := range verr.Violations {
+		rules[v.Path] = v.Rule
+	}
+	if rules["/summary"] != "maxLength" || rules["/sentiment"] != "enum" || len(rules) != 2 {
+		t.Errorf("unexpected violations: %+v", verr.Violations)
+	}
+
+	// Missing and unknown properties are reported too
+	mock.Response = `{"summary": "Comfy", "stars": 5}`
+	_, err = gen.Run(context.Background(), "Great chair")
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected a *ValidationError, got %v", err)
+	}
+	rules = map[string]string{}
+	for _, v := range verr.Violations {
+		rules[v.Path] = v.Rule
+	}
+	if rules["/sentiment"] != "required" || rules["/stars"] != "additionalProperties" || len(rules) != 2 {
+		t.Errorf("unexpected violations: %+v", verr.Violations)
+	}
+}
@@ -28,14 +28,12 @@ type ChatResponse struct {
 
 func main() {
 	chat, err := promptgen.Create[ChatInput, ChatResponse](`
-        Chat History:
+        {{system}}
+        Respond naturally while detecting the intent and key topics.
         {{range .History}}
-        {{.Role}}: {{.Content}}
+        {{role .Role}}{{.Content}}
         {{end}}
-
-        User: {{.Query}}
-
-        Respond naturally while detecting the intent and key topics.
+        {{user}}{{.Query}}
     `)
 	if err != nil {
 		log.Fatal(err)
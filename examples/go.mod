@@ -1,6 +1,6 @@
 module github.com/arjunsriva/promptgen/examples
 
-go 1.22.1
+go 1.23
 
 toolchain go1.23.5
 
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/arjunsriva/promptgen"
+	"github.com/arjunsriva/promptgen/provider"
 )
 
 // ContentInput represents the content to be moderated
@@ -230,6 +231,22 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
 	defer cancel()
 
+	// Share one set of client-side limits between all generators so that the
+	// parallel fan-out stays within the account's rate limits
+	openAI, err := provider.DefaultOpenAI()
+	if err != nil {
+		log.Fatal(err)
+	}
+	limited := provider.Limited(openAI, provider.Limits{
+		RequestsPerMinute: 500,
+		TokensPerMinute:   30000,
+		MaxInFlight:       4,
+	})
+	checkHarmful.WithProvider(limited)
+	checkSpam.WithProvider(limited)
+	checkInappropriate.WithProvider(limited)
+	getModerationVote.WithProvider(limited)
+
 	examples := []struct {
 		name  string
 		input ContentInput
@@ -2,8 +2,29 @@ package promptgen
 
 import "context"
 
-// Hook represents a function that can intercept and modify requests/responses
+// Hook represents a function that can intercept and modify requests/responses.
+// BeforeRequest is called once per request with the final user message,
+// which carries the response format instructions; system messages and
+// earlier conversation turns are passed to the provider unchanged.
 type Hook interface {
 	BeforeRequest(ctx context.Context, prompt string) (string, error)
 	AfterResponse(ctx context.Context, response string, err error) (string, error)
 }
+
+// StreamHook observes and transforms streamed responses. Streams call it
+// instead of AfterResponse for hooks that implement both. Hooks that only
+// implement Hook have AfterResponse called once on the full text when a
+// stream ends, too late to change the chunks already delivered.
+type StreamHook interface {
+	// OnStreamStart is called with the final prompt before the stream opens
+	OnStreamStart(ctx context.Context, prompt string) error
+
+	// OnChunk is called for every chunk and may transform it before it is
+	// delivered. Returning an empty string drops the chunk.
+	OnChunk(ctx context.Context, chunk string) (string, error)
+
+	// OnStreamEnd is called once the stream ends with the full, unmodified
+	// response text, so content spanning chunk boundaries can be inspected.
+	// Returning an error fails the stream.
+	OnStreamEnd(ctx context.Context, fullText string, err error) error
+}
@@ -30,3 +30,23 @@ func (h *LoggingHook) AfterResponse(_ context.Context, response string, err erro
 	h.Logger.Printf("Received response from provider:\n%s\n", response)
 	return response, nil
 }
+
+// OnStreamStart only marks the start of the stream, as BeforeRequest has
+// already logged the prompt
+func (h *LoggingHook) OnStreamStart(_ context.Context, _ string) error {
+	h.Logger.Printf("Starting stream\n")
+	return nil
+}
+
+func (h *LoggingHook) OnChunk(_ context.Context, chunk string) (string, error) {
+	return chunk, nil
+}
+
+func (h *LoggingHook) OnStreamEnd(_ context.Context, fullText string, err error) error {
+	if err != nil {
+		h.Logger.Printf("Stream error: %v\n", err)
+		return nil
+	}
+	h.Logger.Printf("Received streamed response from provider:\n%s\n", fullText)
+	return nil
+}
@@ -50,6 +50,34 @@ func TestLoggingHook(t *testing.T) {
 		}
 	})
 
+	// Test stream logging only logs at the start and end
+	t.Run("stream", func(t *testing.T) {
+		buf.Reset()
+		hook.OnStreamStart(ctx, "test prompt")
+		for _, chunk := range []string{"test ", "response"} {
+			if got, _ := hook.OnChunk(ctx, chunk); got != chunk {
+				t.Errorf("OnChunk() = %q, want %q", got, chunk)
+			}
+		}
+		hook.OnStreamEnd(ctx, "test response", nil)
+		got := buf.String()
+		want := "Starting stream\nReceived streamed response from provider:\ntest response\n"
+		if got != want {
+			t.Errorf("stream logged %q, want %q", got, want)
+		}
+	})
+
+	// Test OnStreamEnd with error
+	t.Run("OnStreamEnd with error", func(t *testing.T) {
+		buf.Reset()
+		hook.OnStreamEnd(ctx, "partial", errors.New("test error"))
+		got := buf.String()
+		want := "Stream error: test error\n"
+		if got != want {
+			t.Errorf("OnStreamEnd() logged %q, want %q", got, want)
+		}
+	})
+
 	// Test with nil logger
 	t.Run("nil logger", func(t *testing.T) {
 		hook := NewLoggingHook(nil)
@@ -2,6 +2,7 @@ package promptgen
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/arjunsriva/promptgen/provider"
@@ -29,6 +30,48 @@ func TestHooks(t *testing.T) {
 	}
 }
 
+func TestHooksMessages(t *testing.T) {
+	mockProvider := &provider.MockProvider{
+		Response: `{"response": "Hello"}`,
+	}
+	hook := &recordingHook{}
+
+	gen, _ := Create[TestInput, TestOutput]("{{system}}Be brief.{{user}}Hello {{.Message}}")
+	gen.WithProvider(mockProvider).WithHook(hook)
+
+	if _, err := gen.Run(context.Background(), TestInput{Message: "test"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(hook.prompts) != 1 {
+		t.Fatalf("BeforeRequest called %d times, want once per request", len(hook.prompts))
+	}
+	if !strings.HasPrefix(hook.prompts[0], "Hello test") {
+		t.Errorf("BeforeRequest got %q, want the final user message", hook.prompts[0])
+	}
+	messages := mockProvider.Requests[0].Messages
+	if messages[0].Content != "Be brief." {
+		t.Errorf("system message = %q, want it unchanged", messages[0].Content)
+	}
+	if !strings.HasSuffix(messages[1].Content, "[checked]") {
+		t.Errorf("user message = %q, want the hook's rewrite", messages[1].Content)
+	}
+}
+
+// recordingHook records the prompts it sees and marks them as checked
+type recordingHook struct {
+	prompts []string
+}
+
+func (h *recordingHook) BeforeRequest(_ context.Context, prompt string) (string, error) {
+	h.prompts = append(h.prompts, prompt)
+	return prompt + " [checked]", nil
+}
+
+func (h *recordingHook) AfterResponse(_ context.Context, response string, err error) (string, error) {
+	return response, err
+}
+
 type TestHook struct {
 	beforeCalled   bool
 	beforeResponse string
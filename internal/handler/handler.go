@@ -1,7 +1,11 @@
 // Package handler defines the core interface for processing different output types
 package handler
 
-import "fmt"
+import (
+	"fmt"
+	"math/big"
+	"time"
+)
 
 // Handler defines how different output types are processed
 type Handler[O any] interface {
@@ -15,6 +19,34 @@ type Handler[O any] interface {
 	Validate(O) error
 }
 
+// PartialParser is implemented by handlers that can parse incomplete
+// responses while they are still being streamed
+type PartialParser[O any] interface {
+	// ParsePartial parses as much of the response as possible, reporting
+	// false if nothing could be parsed yet
+	ParsePartial(response string) (O, bool)
+}
+
+// RawValidator is implemented by handlers that validate the response as the
+// model sent it rather than the parsed output, so that rules lost in parsing,
+// such as required or unknown properties, are still checked. Generators call
+// it instead of Validate once the response has been parsed.
+type RawValidator interface {
+	// ValidateRaw checks if the response meets requirements
+	ValidateRaw(response string) error
+}
+
+// SchemaHandler is implemented by handlers whose output is described by a
+// JSON Schema
+type SchemaHandler interface {
+	// Schema returns the JSON Schema responses must conform to
+	Schema() (string, error)
+
+	// WrapPromptStructured adds type-specific instructions to the base prompt
+	// for providers that constrain responses to JSON natively
+	WrapPromptStructured(basePrompt string) string
+}
+
 // Type represents the kind of handler needed
 type Type int
 
@@ -47,7 +79,10 @@ func DetermineType[O any]() Type {
 	switch any(zero).(type) {
 	case string:
 		return TypeString
-	case int, float64, bool:
+	case int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64,
+		float32, float64, bool,
+		time.Time, time.Duration, *big.Int, *big.Float:
 		return TypePrimitive
 	default:
 		return TypeJSON
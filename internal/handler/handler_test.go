@@ -1,6 +1,10 @@
 package handler
 
-import "testing"
+import (
+	"math/big"
+	"testing"
+	"time"
+)
 
 type TestStruct struct {
 	Field string
@@ -19,6 +23,49 @@ func TestDetermineType(t *testing.T) {
 			},
 			want: TypeString,
 		},
+		{
+			name: "integer widths",
+			testType: func() Type {
+				return DetermineType[uint32]()
+			},
+			want: TypePrimitive,
+		},
+		{
+			name: "float32",
+			testType: func() Type {
+				return DetermineType[float32]()
+			},
+			want: TypePrimitive,
+		},
+		{
+			name: "time",
+			testType: func() Type {
+				return DetermineType[time.Time]()
+			},
+			want: TypePrimitive,
+		},
+		{
+			name: "duration",
+			testType: func() Type {
+				return DetermineType[time.Duration]()
+			},
+			want: TypePrimitive,
+		},
+		{
+			name: "big int",
+			testType: func() Type {
+				return DetermineType[*big.Int]()
+			},
+			want: TypePrimitive,
+		},
+		{
+			name: "named integer",
+			testType: func() Type {
+				type Level int
+				return DetermineType[Level]()
+			},
+			want: TypeJSON,
+		},
 		{
 			name: "struct type",
 			testType: func() Type {
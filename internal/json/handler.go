@@ -7,16 +7,17 @@ import (
 	"regexp"
 
 	"github.com/arjunsriva/promptgen/internal/handler"
+	"github.com/arjunsriva/promptgen/schema"
 )
 
 // Handler handles JSON/struct input/output
 type Handler[O any] struct {
-	validator *Validator[O]
+	validator *schema.Validator[O]
 }
 
 // New creates a new JSON handler
 func New[O any]() (handler.Handler[O], error) {
-	validator, err := NewValidator[O]()
+	validator, err := schema.NewValidator[O]()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create validator: %w", err)
 	}
@@ -25,41 +26,66 @@ func New[O any]() (handler.Handler[O], error) {
 	}, nil
 }
 
+// NewWithValidator creates a JSON handler that describes and validates
+// output with the given validator
+func NewWithValidator[O any](validator *schema.Validator[O]) handler.Handler[O] {
+	return &Handler[O]{
+		validator: validator,
+	}
+}
+
 func (h *Handler[O]) WrapPrompt(basePrompt string) string {
-	schema, _ := h.validator.SchemaString()
 	return fmt.Sprintf(`%s
 
 Format your response according to this JSON schema, pay close attention to the validation rules in the schema:
 %s
 
 Provide the result enclosed in triple backticks with 'json' on the first line.
-Don't put control characters in the wrong place or the JSON will be invalid.`, basePrompt, schema)
+Don't put control characters in the wrong place or the JSON will be invalid.`, basePrompt, h.validator)
+}
+
+// WrapPromptStructured describes the schema without asking for a fenced code
+// block, since the provider already constrains the response to JSON
+func (h *Handler[O]) WrapPromptStructured(basePrompt string) string {
+	return fmt.Sprintf(`%s
+
+Respond with a JSON object that conforms to this JSON schema, pay close attention to the validation rules in the schema:
+%s`, basePrompt, h.validator)
+}
+
+// Schema returns the JSON Schema generated from O
+func (h *Handler[O]) Schema() (string, error) {
+	return h.validator.String(), nil
 }
 
 func (h *Handler[O]) Parse(response string) (O, error) {
 	var output O
 
-	// Extract JSON from markdown code blocks if present
-	jsonStr := response
-	if matches := jsonRegex.FindStringSubmatch(response); len(matches) >= 2 {
-		jsonStr = matches[1]
-	}
-
-	if err := json.Unmarshal([]byte(jsonStr), &output); err != nil {
+	if err := json.Unmarshal([]byte(extractJSON(response)), &output); err != nil {
 		return output, fmt.Errorf("failed to parse JSON: %w", err)
 	}
 
 	return output, nil
 }
 
+// Validate checks output re-encoded as JSON, which cannot tell missing or
+// unknown properties apart from zero values; ValidateRaw checks them too
 func (h *Handler[O]) Validate(output O) error {
-	// Convert to JSON for validation
-	jsonBytes, err := json.Marshal(output)
-	if err != nil {
-		return fmt.Errorf("failed to marshal output: %w", err)
-	}
+	return h.validator.ValidateValue(output)
+}
+
+// ValidateRaw validates the JSON in response against the schema
+func (h *Handler[O]) ValidateRaw(response string) error {
+	return h.validator.Validate([]byte(extractJSON(response)))
+}
 
-	return h.validator.Validate(jsonBytes)
+// extractJSON returns the JSON in response, taking it from a markdown code
+// block if present
+func extractJSON(response string) string {
+	if matches := jsonRegex.FindStringSubmatch(response); len(matches) >= 2 {
+		return matches[1]
+	}
+	return response
 }
 
 // Regex for extracting JSON from markdown code blocks
@@ -1,7 +1,11 @@
 package json
 
 import (
+	"errors"
+	"strings"
 	"testing"
+
+	"github.com/arjunsriva/promptgen/schema"
 )
 
 type handlerTestOutput struct {
@@ -21,6 +25,16 @@ func TestJSONHandler(t *testing.T) {
 		}
 	})
 
+	t.Run("wrap prompt structured", func(t *testing.T) {
+		prompt := handler.(*Handler[handlerTestOutput]).WrapPromptStructured("Generate a message")
+		if !strings.HasPrefix(prompt, "Generate a message") || !strings.Contains(prompt, `"response"`) {
+			t.Errorf("prompt should describe the schema, got %q", prompt)
+		}
+		if strings.Contains(prompt, "```") || strings.Contains(prompt, "backticks") {
+			t.Errorf("prompt should not ask for a code block, got %q", prompt)
+		}
+	})
+
 	t.Run("parse valid JSON with markdown", func(t *testing.T) {
 		input := "```json\n{\"response\":\"hello\"}\n```"
 		result, err := handler.Parse(input)
@@ -65,6 +79,32 @@ func TestJSONHandler(t *testing.T) {
 			t.Logf("Got error: %v", err)
 		}
 	})
+
+	t.Run("validate raw response", func(t *testing.T) {
+		raw := handler.(*Handler[handlerTestOutput])
+		if err := raw.ValidateRaw("```json\n{\"response\":\"hello\"}\n```"); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		tests := []struct {
+			response string
+			path     string
+			rule     string
+		}{
+			{response: `{}`, path: "/response", rule: "required"},
+			{response: "```json\n{\"response\":\"hello\",\"extra\":1}\n```", path: "/extra", rule: "additionalProperties"},
+		}
+		for _, tt := range tests {
+			var verr *schema.ValidationError
+			if err := raw.ValidateRaw(tt.response); !errors.As(err, &verr) {
+				t.Errorf("ValidateRaw(%q) = %v, want a *schema.ValidationError", tt.response, err)
+				continue
+			}
+			if len(verr.Violations) != 1 || verr.Violations[0].Path != tt.path || verr.Violations[0].Rule != tt.rule {
+				t.Errorf("ValidateRaw(%q) violations = %+v, want %s at %s", tt.response, verr.Violations, tt.rule, tt.path)
+			}
+		}
+	})
 }
 
 func TestNewJSONHandler(t *testing.T) {
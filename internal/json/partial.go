@@ -0,0 +1,139 @@
+package json
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// ParsePartial parses an incomplete JSON response, such as one that is still
+// being streamed, by closing any open strings, arrays and objects. Incomplete
+// keys and literals are dropped. It reports false if nothing could be parsed yet.
+func (h *Handler[O]) ParsePartial(response string) (O, bool) {
+	var output O
+
+	completed, ok := completeJSON(response)
+	if !ok {
+		return output, false
+	}
+	if err := json.Unmarshal([]byte(completed), &output); err != nil {
+		return output, false
+	}
+	return output, true
+}
+
+// container is an open object or array while scanning partial JSON
+type container struct {
+	closer    byte
+	expectKey bool
+}
+
+// completeJSON turns a prefix of a JSON document into a valid document
+func completeJSON(text string) (string, bool) {
+	start := strings.IndexAny(text, "{[")
+	if start < 0 {
+		return "", false
+	}
+	text = text[start:]
+
+	var (
+		stack        []container
+		inString     bool
+		stringIsKey  bool
+		escapeStart  = -1
+		literalStart = -1
+		safeEnd      = -1
+		safeClosers  string
+	)
+
+	closers := func() string {
+		var b strings.Builder
+		for i := len(stack) - 1; i >= 0; i-- {
+			b.WriteByte(stack[i].closer)
+		}
+		return b.String()
+	}
+	markSafe := func(end int) {
+		safeEnd, safeClosers = end, closers()
+	}
+	endLiteral := func(end int) {
+		if literalStart >= 0 && json.Valid([]byte(text[literalStart:end])) {
+			markSafe(end)
+		}
+		literalStart = -1
+	}
+
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+
+		if inString {
+			switch {
+			case escapeStart >= 0:
+				// \uXXXX escapes span six bytes, others two
+				if text[escapeStart+1] != 'u' || i-escapeStart == 5 {
+					escapeStart = -1
+				}
+			case c == '\\':
+				escapeStart = i
+			case c == '"':
+				inString = false
+				if !stringIsKey {
+					markSafe(i + 1)
+				}
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			endLiteral(i)
+			inString = true
+			stringIsKey = len(stack) > 0 && stack[len(stack)-1].expectKey
+		case '{':
+			stack = append(stack, container{closer: '}', expectKey: true})
+			markSafe(i + 1)
+		case '[':
+			stack = append(stack, container{closer: ']'})
+			markSafe(i + 1)
+		case '}', ']':
+			endLiteral(i)
+			if len(stack) == 0 {
+				return "", false
+			}
+			stack = stack[:len(stack)-1]
+			markSafe(i + 1)
+			if len(stack) == 0 {
+				// The document is complete; ignore anything after it
+				return text[:i+1], true
+			}
+		case ':':
+			endLiteral(i)
+			stack[len(stack)-1].expectKey = false
+		case ',':
+			endLiteral(i)
+			if top := &stack[len(stack)-1]; top.closer == '}' {
+				top.expectKey = true
+			}
+		case ' ', '\t', '\n', '\r':
+			endLiteral(i)
+		default:
+			if literalStart < 0 {
+				literalStart = i
+			}
+		}
+	}
+
+	// Keep a partially streamed string value so text fields fill progressively
+	if inString && !stringIsKey {
+		end := len(text)
+		if escapeStart >= 0 {
+			end = escapeStart
+		}
+		return text[:end] + `"` + closers(), true
+	}
+	endLiteral(len(text))
+
+	if safeEnd < 0 {
+		return "", false
+	}
+	return text[:safeEnd] + safeClosers, true
+}
@@ -0,0 +1,72 @@
+package json
+
+import (
+	"testing"
+)
+
+func TestCompleteJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		want   string
+		wantOK bool
+	}{
+		{name: "empty", input: "", wantOK: false},
+		{name: "no json yet", input: "```js", wantOK: false},
+		{name: "open object", input: "{", want: "{}", wantOK: true},
+		{name: "partial key", input: `{"ti`, want: "{}", wantOK: true},
+		{name: "key without value", input: `{"title":`, want: "{}", wantOK: true},
+		{name: "partial string value", input: `{"title": "Ergo`, want: `{"title": "Ergo"}`, wantOK: true},
+		{name: "complete value", input: `{"title": "Chair",`, want: `{"title": "Chair"}`, wantOK: true},
+		{name: "second partial key", input: `{"title": "Chair", "desc`, want: `{"title": "Chair"}`, wantOK: true},
+		{name: "partial number", input: `{"age": 4`, want: `{"age": 4}`, wantOK: true},
+		{name: "incomplete number", input: `{"score": 4.`, want: `{}`, wantOK: true},
+		{name: "incomplete literal", input: `{"a": 1, "ok": tr`, want: `{"a": 1}`, wantOK: true},
+		{name: "complete literal", input: `{"ok": true`, want: `{"ok": true}`, wantOK: true},
+		{name: "nested array", input: `{"tags": ["a", "b`, want: `{"tags": ["a", "b"]}`, wantOK: true},
+		{name: "nested object", input: `{"meta": {"k": "v"}, "x": [`, want: `{"meta": {"k": "v"}, "x": []}`, wantOK: true},
+		{name: "dangling escape", input: `{"q": "say \`, want: `{"q": "say "}`, wantOK: true},
+		{name: "partial unicode escape", input: `{"q": "caf\u00`, want: `{"q": "caf"}`, wantOK: true},
+		{name: "complete unicode escape", input: `{"q": "café`, want: `{"q": "café"}`, wantOK: true},
+		{name: "escaped quote", input: `{"q": "a \"quote`, want: `{"q": "a \"quote"}`, wantOK: true},
+		{name: "markdown fence", input: "```json\n{\"title\": \"Hi\"", want: `{"title": "Hi"}`, wantOK: true},
+		{name: "complete document", input: "```json\n{\"title\": \"Hi\"}\n```", want: `{"title": "Hi"}`, wantOK: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := completeJSON(tt.input)
+			if ok != tt.wantOK {
+				t.Fatalf("completeJSON(%q) ok = %v, want %v", tt.input, ok, tt.wantOK)
+			}
+			if got != tt.want {
+				t.Errorf("completeJSON(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePartial(t *testing.T) {
+	type product struct {
+		Title       string   `json:"title"`
+		Description string   `json:"description"`
+		Tags        []string `json:"tags"`
+	}
+
+	h := &Handler[product]{}
+	full := `{"title": "Chair", "description": "Comfy seat", "tags": ["office"]}`
+
+	var last product
+	for i := 1; i <= len(full); i++ {
+		if got, ok := h.ParsePartial(full[:i]); ok {
+			last = got
+		}
+		if i == len(`{"title": "Chai`) && last.Title != "Chai" {
+			t.Errorf("expected partial title 'Chai', got %q", last.Title)
+		}
+	}
+
+	if last.Title != "Chair" || last.Description != "Comfy seat" || len(last.Tags) != 1 {
+		t.Errorf("unexpected final value: %+v", last)
+	}
+}
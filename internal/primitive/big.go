@@ -0,0 +1,104 @@
+package primitive
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/arjunsriva/promptgen/internal/handler"
+)
+
+// BigInt handles *big.Int input/output, for integers of any size
+type BigInt[O any] struct{}
+
+// NewBigInt creates a new big integer handler
+func NewBigInt[O any]() (handler.Handler[O], error) {
+	return &BigInt[O]{}, nil
+}
+
+func (h *BigInt[O]) WrapPrompt(basePrompt string) string {
+	return fmt.Sprintf(`%s
+
+Provide your response as a single integer number, written out in full with every digit.
+Do not use scientific notation, units, symbols, or additional text.
+Examples: 42, -17, 340282366920938463463374607431768211456`, basePrompt)
+}
+
+func (h *BigInt[O]) Parse(response string) (O, error) {
+	var output O
+	cleaned := cleanNumber(response)
+
+	num, ok := new(big.Int).SetString(cleaned, 10)
+	if !ok {
+		return output, fmt.Errorf("failed to parse integer from: %s", response)
+	}
+	if v, ok := any(num).(O); ok {
+		return v, nil
+	}
+	return output, fmt.Errorf("failed to convert integer to output type")
+}
+
+func (h *BigInt[O]) Validate(output O) error {
+	num, ok := any(output).(*big.Int)
+	if !ok {
+		return fmt.Errorf("expected *big.Int output, got %T", output)
+	}
+	if num == nil {
+		return fmt.Errorf("output integer cannot be nil")
+	}
+	return nil
+}
+
+// BigFloat handles *big.Float input/output, for decimals of any precision
+type BigFloat[O any] struct{}
+
+// NewBigFloat creates a new big float handler
+func NewBigFloat[O any]() (handler.Handler[O], error) {
+	return &BigFloat[O]{}, nil
+}
+
+func (h *BigFloat[O]) WrapPrompt(basePrompt string) string {
+	return fmt.Sprintf(`%s
+
+Provide your response as a single decimal number with as many digits as needed.
+Use a period (.) as the decimal separator.
+Do not include any units, symbols, or additional text.
+Examples: 3.14159265358979323846, -2.5, 0.0, 42.0`, basePrompt)
+}
+
+func (h *BigFloat[O]) Parse(response string) (O, error) {
+	var output O
+	cleaned := cleanNumber(response)
+
+	// Keep every digit the model gave, with at least float64 precision
+	prec := max(uint(len(cleaned))*4, 64)
+	num, _, err := big.ParseFloat(cleaned, 10, prec, big.ToNearestEven)
+	if err != nil {
+		return output, fmt.Errorf("failed to parse float: %v", err)
+	}
+	if v, ok := any(num).(O); ok {
+		return v, nil
+	}
+	return output, fmt.Errorf("failed to convert float to output type")
+}
+
+func (h *BigFloat[O]) Validate(output O) error {
+	num, ok := any(output).(*big.Float)
+	if !ok {
+		return fmt.Errorf("expected *big.Float output, got %T", output)
+	}
+	if num == nil {
+		return fmt.Errorf("output float cannot be nil")
+	}
+	if num.IsInf() {
+		return fmt.Errorf("output float cannot be infinite")
+	}
+	return nil
+}
+
+// cleanNumber trims a numeric response and drops the digit separators
+// models add to long numbers
+func cleanNumber(response string) string {
+	cleaned := strings.TrimSpace(response)
+	return strings.NewReplacer(",", "", "_", "", " ", "").Replace(cleaned)
+}
@@ -0,0 +1,84 @@
+package primitive
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestBigIntParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{name: "small", input: "42", want: "42"},
+		{name: "negative", input: "-17", want: "-17"},
+		{name: "beyond 64 bits", input: "340282366920938463463374607431768211456", want: "340282366920938463463374607431768211456"},
+		{name: "digit separators", input: " 1,000,000_000 ", want: "1000000000"},
+		{name: "invalid - decimal", input: "3.14", wantErr: true},
+		{name: "invalid - scientific", input: "1e10", wantErr: true},
+		{name: "invalid - empty", input: "", wantErr: true},
+	}
+
+	h := &BigInt[*big.Int]{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := h.Parse(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && got.String() != tt.want {
+				t.Errorf("Parse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBigIntValidate(t *testing.T) {
+	h := &BigInt[*big.Int]{}
+	if err := h.Validate(big.NewInt(1)); err != nil {
+		t.Errorf("Validate() error = %v, want nil", err)
+	}
+	if err := h.Validate(nil); err == nil {
+		t.Error("Validate() should fail for nil")
+	}
+}
+
+func TestBigFloatParse(t *testing.T) {
+	h := &BigFloat[*big.Float]{}
+
+	pi := "3.14159265358979323846264338327950288"
+	got, err := h.Parse(pi)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	// Every digit survives, beyond what a float64 could hold
+	if s := got.Text('f', 35); s != pi {
+		t.Errorf("Parse() = %s, want %s", s, pi)
+	}
+
+	got, err = h.Parse("-1,234.5")
+	if err != nil || got.String() != "-1234.5" {
+		t.Errorf("Parse() = %v, %v, want -1234.5", got, err)
+	}
+
+	if _, err := h.Parse("about three"); err == nil {
+		t.Error("Parse() should fail for text")
+	}
+}
+
+func TestBigFloatValidate(t *testing.T) {
+	h := &BigFloat[*big.Float]{}
+	if err := h.Validate(big.NewFloat(1.5)); err != nil {
+		t.Errorf("Validate() error = %v, want nil", err)
+	}
+	if err := h.Validate(nil); err == nil {
+		t.Error("Validate() should fail for nil")
+	}
+	if err := h.Validate(new(big.Float).SetInf(false)); err == nil || !strings.Contains(err.Error(), "infinite") {
+		t.Errorf("Validate() error = %v, want infinite error", err)
+	}
+}
@@ -0,0 +1,107 @@
+package primitive
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arjunsriva/promptgen/internal/handler"
+)
+
+// Duration handles time.Duration input/output
+type Duration[O any] struct{}
+
+// NewDuration creates a new duration handler
+func NewDuration[O any]() (handler.Handler[O], error) {
+	return &Duration[O]{}, nil
+}
+
+func (h *Duration[O]) WrapPrompt(basePrompt string) string {
+	return fmt.Sprintf(`%s
+
+Provide your response as a single duration made of numbers with units: h for hours, m for minutes, s for seconds, ms for milliseconds.
+Do not include spaces or any additional text.
+Examples: 1h30m, 45s, 2h, 250ms`, basePrompt)
+}
+
+// durationUnits maps the unit names models use to their length
+var durationUnits = map[string]time.Duration{
+	"ns": time.Nanosecond, "nanosecond": time.Nanosecond, "nanoseconds": time.Nanosecond,
+	"us": time.Microsecond, "µs": time.Microsecond, "microsecond": time.Microsecond, "microseconds": time.Microsecond,
+	"ms": time.Millisecond, "millisecond": time.Millisecond, "milliseconds": time.Millisecond,
+	"s": time.Second, "sec": time.Second, "secs": time.Second, "second": time.Second, "seconds": time.Second,
+	"m": time.Minute, "min": time.Minute, "mins": time.Minute, "minute": time.Minute, "minutes": time.Minute,
+	"h": time.Hour, "hr": time.Hour, "hrs": time.Hour, "hour": time.Hour, "hours": time.Hour,
+	"d": 24 * time.Hour, "day": 24 * time.Hour, "days": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour, "week": 7 * 24 * time.Hour, "weeks": 7 * 24 * time.Hour,
+}
+
+// durationPart matches one number and unit, e.g. "1.5 hours"
+var durationPart = regexp.MustCompile(`(\d+(?:\.\d+)?)\s*([a-zµ]+)`)
+
+func (h *Duration[O]) Parse(response string) (O, error) {
+	var output O
+	d, err := parseDuration(response)
+	if err != nil {
+		return output, err
+	}
+	if v, ok := any(d).(O); ok {
+		return v, nil
+	}
+	return output, fmt.Errorf("failed to convert duration to output type")
+}
+
+// parseDuration parses Go duration syntax as well as spelled out durations
+// such as "1 hour and 30 minutes" or "2 days"
+func parseDuration(response string) (time.Duration, error) {
+	cleaned := strings.ToLower(strings.Trim(strings.TrimSpace(response), "\"'`."))
+	if d, err := time.ParseDuration(strings.ReplaceAll(cleaned, " ", "")); err == nil {
+		return d, nil
+	}
+
+	negative := strings.HasPrefix(cleaned, "-")
+	rest := strings.TrimPrefix(cleaned, "-")
+
+	var total float64
+	matches := durationPart.FindAllStringSubmatchIndex(rest, -1)
+	last := 0
+	for _, m := range matches {
+		// Only separators may appear between the parts
+		if between := strings.Trim(rest[last:m[0]], " ,"); between != "" && between != "and" {
+			return 0, fmt.Errorf("failed to parse duration from: %s", response)
+		}
+		last = m[1]
+
+		num, err := strconv.ParseFloat(rest[m[2]:m[3]], 64)
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse duration from: %s", response)
+		}
+		unit, ok := durationUnits[rest[m[4]:m[5]]]
+		if !ok {
+			return 0, fmt.Errorf("failed to parse duration: unknown unit %q", rest[m[4]:m[5]])
+		}
+		total += num * float64(unit)
+	}
+	if len(matches) == 0 || strings.TrimSpace(rest[last:]) != "" {
+		return 0, fmt.Errorf("failed to parse duration from: %s", response)
+	}
+
+	if total > math.MaxInt64 {
+		return 0, fmt.Errorf("failed to parse duration: %s is out of range", response)
+	}
+	if negative {
+		total = -total
+	}
+	return time.Duration(total), nil
+}
+
+func (h *Duration[O]) Validate(output O) error {
+	// For durations, we just ensure it's the right type
+	if _, ok := any(output).(time.Duration); !ok {
+		return fmt.Errorf("expected duration output, got %T", output)
+	}
+	return nil
+}
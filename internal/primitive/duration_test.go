@@ -0,0 +1,63 @@
+package primitive
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDurationWrapPrompt(t *testing.T) {
+	h := &Duration[time.Duration]{}
+	got := h.WrapPrompt("How long does it take?")
+	if !strings.HasPrefix(got, "How long does it take?\n\n") || !strings.Contains(got, "1h30m") {
+		t.Errorf("WrapPrompt() = %q, want duration instructions", got)
+	}
+}
+
+func TestDurationParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "go syntax", input: "1h30m", want: 90 * time.Minute},
+		{name: "milliseconds", input: "250ms", want: 250 * time.Millisecond},
+		{name: "with spaces", input: " 1h 30m ", want: 90 * time.Minute},
+		{name: "negative", input: "-45s", want: -45 * time.Second},
+		{name: "quoted", input: "`2h`", want: 2 * time.Hour},
+		{name: "words", input: "1 hour and 30 minutes", want: 90 * time.Minute},
+		{name: "abbreviated words", input: "2 hrs, 15 mins", want: 2*time.Hour + 15*time.Minute},
+		{name: "fractional", input: "1.5 hours", want: 90 * time.Minute},
+		{name: "days", input: "2 days", want: 48 * time.Hour},
+		{name: "weeks", input: "1w", want: 7 * 24 * time.Hour},
+		{name: "capitalized", input: "45 Seconds.", want: 45 * time.Second},
+		{name: "invalid - no unit", input: "90", wantErr: true},
+		{name: "invalid - unknown unit", input: "3 fortnights", wantErr: true},
+		{name: "invalid - extra text", input: "about 5 minutes", wantErr: true},
+		{name: "invalid - empty", input: "", wantErr: true},
+	}
+
+	h := &Duration[time.Duration]{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := h.Parse(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("Parse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDurationValidate(t *testing.T) {
+	if err := (&Duration[time.Duration]{}).Validate(time.Second); err != nil {
+		t.Errorf("Validate() error = %v, want nil", err)
+	}
+	if err := (&Duration[string]{}).Validate("1s"); err == nil {
+		t.Error("Validate() should fail with wrong type")
+	}
+}
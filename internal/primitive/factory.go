@@ -2,6 +2,8 @@ package primitive
 
 import (
 	"fmt"
+	"math/big"
+	"time"
 
 	"github.com/arjunsriva/promptgen/internal/handler"
 )
@@ -12,12 +14,20 @@ func New[O any]() (handler.Handler[O], error) {
 	switch any(zero).(type) {
 	case string:
 		return NewString[O]()
-	case int:
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
 		return NewInt[O]()
-	case float64:
+	case float32, float64:
 		return NewFloat[O]()
 	case bool:
 		return NewBool[O]()
+	case time.Time:
+		return NewTime[O]()
+	case time.Duration:
+		return NewDuration[O]()
+	case *big.Int:
+		return NewBigInt[O]()
+	case *big.Float:
+		return NewBigFloat[O]()
 	default:
 		return nil, fmt.Errorf("type %T is not a supported primitive type", zero)
 	}
@@ -1,7 +1,9 @@
 package primitive
 
 import (
+	"math/big"
 	"testing"
+	"time"
 )
 
 func TestNew(t *testing.T) {
@@ -49,6 +51,45 @@ func TestNew(t *testing.T) {
 		}
 	})
 
+	t.Run("other widths", func(t *testing.T) {
+		if h, err := New[uint16](); err != nil {
+			t.Errorf("New[uint16]() error = %v", err)
+		} else if _, ok := h.(*Int[uint16]); !ok {
+			t.Errorf("New[uint16]() returned wrong type = %T", h)
+		}
+		if h, err := New[float32](); err != nil {
+			t.Errorf("New[float32]() error = %v", err)
+		} else if _, ok := h.(*Float[float32]); !ok {
+			t.Errorf("New[float32]() returned wrong type = %T", h)
+		}
+	})
+
+	t.Run("time types", func(t *testing.T) {
+		if h, err := New[time.Time](); err != nil {
+			t.Errorf("New[time.Time]() error = %v", err)
+		} else if _, ok := h.(*Time[time.Time]); !ok {
+			t.Errorf("New[time.Time]() returned wrong type = %T", h)
+		}
+		if h, err := New[time.Duration](); err != nil {
+			t.Errorf("New[time.Duration]() error = %v", err)
+		} else if _, ok := h.(*Duration[time.Duration]); !ok {
+			t.Errorf("New[time.Duration]() returned wrong type = %T", h)
+		}
+	})
+
+	t.Run("big types", func(t *testing.T) {
+		if h, err := New[*big.Int](); err != nil {
+			t.Errorf("New[*big.Int]() error = %v", err)
+		} else if _, ok := h.(*BigInt[*big.Int]); !ok {
+			t.Errorf("New[*big.Int]() returned wrong type = %T", h)
+		}
+		if h, err := New[*big.Float](); err != nil {
+			t.Errorf("New[*big.Float]() error = %v", err)
+		} else if _, ok := h.(*BigFloat[*big.Float]); !ok {
+			t.Errorf("New[*big.Float]() returned wrong type = %T", h)
+		}
+	})
+
 	t.Run("unsupported type", func(t *testing.T) {
 		type TestStruct struct {
 			Field string
@@ -1,14 +1,17 @@
 package primitive
 
 import (
+	"errors"
 	"fmt"
+	"math"
+	"reflect"
 	"strconv"
 	"strings"
 
 	"github.com/arjunsriva/promptgen/internal/handler"
 )
 
-// Float handles float64 input/output
+// Float handles float32 and float64 input/output
 type Float[O any] struct{}
 
 // NewFloat creates a new float handler
@@ -27,26 +30,47 @@ Examples: 3.14, -2.5, 0.0, 42.0`, basePrompt)
 
 func (h *Float[O]) Parse(response string) (O, error) {
 	var output O
+	bits, ok := floatBits[O]()
+	if !ok {
+		return output, fmt.Errorf("failed to convert float to output type")
+	}
+
 	// Clean up the response
 	cleaned := strings.TrimSpace(response)
 
-	// Parse the float
-	num, err := strconv.ParseFloat(cleaned, 64)
+	// Parse the float, rejecting values the type cannot hold
+	num, err := strconv.ParseFloat(cleaned, bits)
+	if errors.Is(err, strconv.ErrRange) {
+		return output, fmt.Errorf("failed to parse float: %s out of range for float%d", cleaned, bits)
+	}
 	if err != nil {
 		return output, fmt.Errorf("failed to parse float: %v", err)
 	}
-
-	// Type assert to output type
-	if v, ok := any(num).(O); ok {
-		return v, nil
+	// ParseFloat also accepts spellings of infinity and NaN
+	if math.IsInf(num, 0) || math.IsNaN(num) {
+		return output, fmt.Errorf("failed to parse float: %s is not a finite number", cleaned)
 	}
-	return output, fmt.Errorf("failed to convert float to output type")
+
+	reflect.ValueOf(&output).Elem().SetFloat(num)
+	return output, nil
 }
 
 func (h *Float[O]) Validate(output O) error {
 	// For floats, we just ensure it's the right type
-	if _, ok := any(output).(float64); !ok {
-		return fmt.Errorf("expected float64 output, got %T", output)
+	if _, ok := floatBits[O](); !ok {
+		return fmt.Errorf("expected float output, got %T", output)
 	}
 	return nil
 }
+
+// floatBits returns the size of O if it is float32 or float64
+func floatBits[O any]() (int, bool) {
+	switch any(*new(O)).(type) {
+	case float32:
+		return 32, true
+	case float64:
+		return 64, true
+	default:
+		return 0, false
+	}
+}
@@ -1,6 +1,7 @@
 package primitive
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -76,6 +77,21 @@ func TestFloatParse(t *testing.T) {
 			input:   "1.2.3",
 			wantErr: true,
 		},
+		{
+			name:    "invalid - infinity",
+			input:   "inf",
+			wantErr: true,
+		},
+		{
+			name:    "invalid - negative infinity",
+			input:   "-Infinity",
+			wantErr: true,
+		},
+		{
+			name:    "invalid - NaN",
+			input:   "NaN",
+			wantErr: true,
+		},
 	}
 
 	h := &Float[float64]{}
@@ -93,6 +109,24 @@ func TestFloatParse(t *testing.T) {
 	}
 }
 
+func TestFloat32(t *testing.T) {
+	h := &Float[float32]{}
+
+	got, err := h.Parse("2.5")
+	if err != nil || got != 2.5 {
+		t.Errorf("Parse() = %v, %v, want 2.5", got, err)
+	}
+
+	_, err = h.Parse("1e39")
+	if err == nil || !strings.Contains(err.Error(), "out of range for float32") {
+		t.Errorf("Parse() error = %v, want range error", err)
+	}
+
+	if err := h.Validate(float32(1.5)); err != nil {
+		t.Errorf("Validate() error = %v, want nil", err)
+	}
+}
+
 func TestFloatValidate(t *testing.T) {
 	t.Run("valid type", func(t *testing.T) {
 		h := &Float[float64]{}
@@ -1,14 +1,17 @@
 package primitive
 
 import (
+	"errors"
 	"fmt"
+	"math"
+	"reflect"
 	"strconv"
 	"strings"
 
 	"github.com/arjunsriva/promptgen/internal/handler"
 )
 
-// Int handles integer input/output
+// Int handles signed and unsigned integers of every width
 type Int[O any] struct{}
 
 // NewInt creates a new integer handler
@@ -17,35 +20,107 @@ func NewInt[O any]() (handler.Handler[O], error) {
 }
 
 func (h *Int[O]) WrapPrompt(basePrompt string) string {
+	typ, _ := intType[O]()
+	if typ.unsigned {
+		return fmt.Sprintf(`%s
+
+Provide your response as a single non-negative integer number%s.
+Do not include any units, symbols, or additional text.
+Examples: 42, 7, 0`, basePrompt, typ.rangeHint())
+	}
 	return fmt.Sprintf(`%s
 
-Provide your response as a single integer number.
+Provide your response as a single integer number%s.
 Do not include any units, symbols, or additional text.
-Examples: 42, -17, 0`, basePrompt)
+Examples: 42, -17, 0`, basePrompt, typ.rangeHint())
 }
 
 func (h *Int[O]) Parse(response string) (O, error) {
 	var output O
+	typ, ok := intType[O]()
+	if !ok {
+		return output, fmt.Errorf("failed to convert integer to output type")
+	}
+
 	// Clean up the response
 	cleaned := strings.TrimSpace(response)
 
-	// Parse the integer
-	num, err := strconv.Atoi(cleaned)
-	if err != nil {
-		return output, fmt.Errorf("failed to parse integer: %v", err)
-	}
-
-	// Type assert to output type
-	if v, ok := any(num).(O); ok {
-		return v, nil
+	value := reflect.ValueOf(&output).Elem()
+	if typ.unsigned {
+		if strings.HasPrefix(cleaned, "-") {
+			return output, fmt.Errorf("failed to parse integer: %s must not be negative", typ.name)
+		}
+		num, err := strconv.ParseUint(strings.TrimPrefix(cleaned, "+"), 10, typ.bits)
+		if err != nil {
+			return output, typ.parseError(err)
+		}
+		value.SetUint(num)
+	} else {
+		num, err := strconv.ParseInt(cleaned, 10, typ.bits)
+		if err != nil {
+			return output, typ.parseError(err)
+		}
+		value.SetInt(num)
 	}
-	return output, fmt.Errorf("failed to convert integer to output type")
+	return output, nil
 }
 
 func (h *Int[O]) Validate(output O) error {
 	// For integers, we just ensure it's the right type
-	if _, ok := any(output).(int); !ok {
+	if _, ok := intType[O](); !ok {
 		return fmt.Errorf("expected integer output, got %T", output)
 	}
 	return nil
 }
+
+// integer describes an integer type
+type integer struct {
+	name     string
+	bits     int
+	unsigned bool
+}
+
+// intType describes O if it is one of Go's integer types
+func intType[O any]() (integer, bool) {
+	var zero O
+	typ := reflect.TypeOf(zero)
+	if typ == nil || typ.PkgPath() != "" {
+		return integer{}, false
+	}
+
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return integer{name: typ.Name(), bits: typ.Bits()}, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return integer{name: typ.Name(), bits: typ.Bits(), unsigned: true}, true
+	default:
+		return integer{}, false
+	}
+}
+
+// bounds returns the smallest and largest values of the type
+func (t integer) bounds() (string, string) {
+	if t.unsigned {
+		return "0", strconv.FormatUint(math.MaxUint64>>(64-t.bits), 10)
+	}
+	return strconv.FormatInt(math.MinInt64>>(64-t.bits), 10), strconv.FormatInt(math.MaxInt64>>(64-t.bits), 10)
+}
+
+// rangeHint describes the range of types narrower than 64 bits, which a
+// model could plausibly exceed
+func (t integer) rangeHint() string {
+	if t.bits >= 64 {
+		return ""
+	}
+	min, max := t.bounds()
+	return fmt.Sprintf(" between %s and %s", min, max)
+}
+
+// parseError explains why a response is not a valid integer of the type
+func (t integer) parseError(err error) error {
+	if errors.Is(err, strconv.ErrRange) {
+		min, max := t.bounds()
+		return fmt.Errorf("failed to parse integer: out of range for %s (%s to %s)", t.name, min, max)
+	}
+	return fmt.Errorf("failed to parse integer: %v", err)
+}
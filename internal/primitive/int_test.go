@@ -1,6 +1,8 @@
 package primitive
 
 import (
+	"math"
+	"strings"
 	"testing"
 )
 
@@ -80,6 +82,66 @@ func TestIntParse(t *testing.T) {
 	}
 }
 
+func TestIntWidths(t *testing.T) {
+	t.Run("int8 in range", func(t *testing.T) {
+		got, err := (&Int[int8]{}).Parse("-128")
+		if err != nil || got != -128 {
+			t.Errorf("Parse() = %v, %v, want -128", got, err)
+		}
+	})
+
+	t.Run("int8 out of range", func(t *testing.T) {
+		_, err := (&Int[int8]{}).Parse("200")
+		if err == nil || !strings.Contains(err.Error(), "out of range for int8 (-128 to 127)") {
+			t.Errorf("Parse() error = %v, want range error", err)
+		}
+	})
+
+	t.Run("int64", func(t *testing.T) {
+		got, err := (&Int[int64]{}).Parse("9223372036854775807")
+		if err != nil || got != math.MaxInt64 {
+			t.Errorf("Parse() = %v, %v, want %d", got, err, int64(math.MaxInt64))
+		}
+	})
+
+	t.Run("uint64", func(t *testing.T) {
+		got, err := (&Int[uint64]{}).Parse("+18446744073709551615")
+		if err != nil || got != math.MaxUint64 {
+			t.Errorf("Parse() = %v, %v, want %d", got, err, uint64(math.MaxUint64))
+		}
+	})
+
+	t.Run("uint rejects negative", func(t *testing.T) {
+		if _, err := (&Int[uint]{}).Parse("-1"); err == nil {
+			t.Error("Parse() should fail for a negative unsigned integer")
+		}
+	})
+
+	t.Run("uint16 out of range", func(t *testing.T) {
+		_, err := (&Int[uint16]{}).Parse("70000")
+		if err == nil || !strings.Contains(err.Error(), "out of range for uint16 (0 to 65535)") {
+			t.Errorf("Parse() error = %v, want range error", err)
+		}
+	})
+
+	t.Run("prompt mentions range", func(t *testing.T) {
+		got := (&Int[uint8]{}).WrapPrompt("Pick a byte")
+		if !strings.Contains(got, "single non-negative integer number between 0 and 255") {
+			t.Errorf("WrapPrompt() = %q, want range hint", got)
+		}
+		if got := (&Int[int64]{}).WrapPrompt("Count"); strings.Contains(got, "between") {
+			t.Errorf("WrapPrompt() = %q, want no range hint for 64 bits", got)
+		}
+	})
+
+	t.Run("named types are not supported", func(t *testing.T) {
+		type Level int
+		if err := (&Int[Level]{}).Validate(Level(1)); err == nil {
+			t.Error("Validate() should fail for a named integer type")
+		}
+	})
+}
+
 func TestIntValidate(t *testing.T) {
 	t.Run("valid type", func(t *testing.T) {
 		h := &Int[int]{}
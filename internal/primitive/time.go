@@ -0,0 +1,79 @@
+package primitive
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/arjunsriva/promptgen/internal/handler"
+)
+
+// Time handles time.Time input/output
+type Time[O any] struct{}
+
+// NewTime creates a new time handler
+func NewTime[O any]() (handler.Handler[O], error) {
+	return &Time[O]{}, nil
+}
+
+func (h *Time[O]) WrapPrompt(basePrompt string) string {
+	return fmt.Sprintf(`%s
+
+Provide your response as a single date and time in RFC 3339 format, including the time zone offset.
+Do not include any additional text or explanation.
+Examples: 2024-03-15T14:30:00Z, 2024-03-15T09:00:00-05:00`, basePrompt)
+}
+
+// timeLayouts are tried in order when parsing. Layouts without a zone are
+// read as UTC.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC850,
+	time.RFC822Z,
+	time.RFC822,
+	time.ANSIC,
+	"January 2, 2006 15:04",
+	"January 2, 2006",
+	"Jan 2, 2006",
+	"2 January 2006",
+	"2 Jan 2006",
+}
+
+func (h *Time[O]) Parse(response string) (O, error) {
+	var output O
+	// Clean up the response, which models sometimes quote
+	cleaned := strings.Trim(strings.TrimSpace(response), "\"'`")
+	cleaned = strings.TrimSuffix(cleaned, ".")
+
+	for _, layout := range timeLayouts {
+		t, err := time.Parse(layout, cleaned)
+		if err != nil {
+			continue
+		}
+		if v, ok := any(t).(O); ok {
+			return v, nil
+		}
+		return output, fmt.Errorf("failed to convert time to output type")
+	}
+	return output, fmt.Errorf("failed to parse time from: %s", response)
+}
+
+func (h *Time[O]) Validate(output O) error {
+	t, ok := any(output).(time.Time)
+	if !ok {
+		return fmt.Errorf("expected time output, got %T", output)
+	}
+	if t.IsZero() {
+		return fmt.Errorf("output time cannot be zero")
+	}
+	return nil
+}
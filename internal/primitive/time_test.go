@@ -0,0 +1,102 @@
+package primitive
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimeWrapPrompt(t *testing.T) {
+	h := &Time[time.Time]{}
+	got := h.WrapPrompt("When was the meeting?")
+	if !strings.HasPrefix(got, "When was the meeting?\n\n") || !strings.Contains(got, "RFC 3339") {
+		t.Errorf("WrapPrompt() = %q, want RFC 3339 instructions", got)
+	}
+}
+
+func TestTimeParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    time.Time
+		wantErr bool
+	}{
+		{
+			name:  "RFC 3339",
+			input: "2024-03-15T14:30:00Z",
+			want:  time.Date(2024, 3, 15, 14, 30, 0, 0, time.UTC),
+		},
+		{
+			name:  "with offset",
+			input: "2024-03-15T09:30:00-05:00",
+			want:  time.Date(2024, 3, 15, 14, 30, 0, 0, time.UTC),
+		},
+		{
+			name:  "fractional seconds",
+			input: "2024-03-15T14:30:00.5Z",
+			want:  time.Date(2024, 3, 15, 14, 30, 0, 500000000, time.UTC),
+		},
+		{
+			name:  "without zone",
+			input: "2024-03-15T14:30:00",
+			want:  time.Date(2024, 3, 15, 14, 30, 0, 0, time.UTC),
+		},
+		{
+			name:  "space separator",
+			input: "2024-03-15 14:30:00",
+			want:  time.Date(2024, 3, 15, 14, 30, 0, 0, time.UTC),
+		},
+		{
+			name:  "date only",
+			input: "2024-03-15",
+			want:  time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "quoted with whitespace",
+			input: "  \"2024-03-15T14:30:00Z\"\n",
+			want:  time.Date(2024, 3, 15, 14, 30, 0, 0, time.UTC),
+		},
+		{
+			name:  "written out",
+			input: "March 15, 2024",
+			want:  time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "invalid - text",
+			input:   "next Tuesday",
+			wantErr: true,
+		},
+		{
+			name:    "invalid - empty",
+			input:   "",
+			wantErr: true,
+		},
+	}
+
+	h := &Time[time.Time]{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := h.Parse(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !got.Equal(tt.want) {
+				t.Errorf("Parse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimeValidate(t *testing.T) {
+	h := &Time[time.Time]{}
+	if err := h.Validate(time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Errorf("Validate() error = %v, want nil", err)
+	}
+	if err := h.Validate(time.Time{}); err == nil {
+		t.Error("Validate() should fail for the zero time")
+	}
+	if err := (&Time[string]{}).Validate("2024-03-15"); err == nil {
+		t.Error("Validate() should fail with wrong type")
+	}
+}
@@ -0,0 +1,98 @@
+package promptgen
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/arjunsriva/promptgen/provider"
+)
+
+// roleMarker is emitted by the role template functions and later used to
+// split the rendered prompt into messages. Each render appends a random
+// nonce, so that template data cannot forge message boundaries.
+const roleMarker = "\x00promptgen:role:"
+
+// templateFuncs are available in every prompt template. They start a new
+// message with the given role:
+//
+//	{{system}}You are a helpful assistant.
+//	{{range .History}}{{role .Role}}{{.Content}}{{end}}
+//	{{user}}{{.Query}}
+//
+// These are only used to parse templates; render replaces them with
+// functions emitting the marker for that render.
+var templateFuncs = roleFuncs(roleMarker)
+
+// roleFuncs returns the role template functions, emitting marker before
+// the role name
+func roleFuncs(marker string) template.FuncMap {
+	return template.FuncMap{
+		"system":    func() string { return marker + string(provider.RoleSystem) + "\x00" },
+		"user":      func() string { return marker + string(provider.RoleUser) + "\x00" },
+		"assistant": func() string { return marker + string(provider.RoleAssistant) + "\x00" },
+		"role":      func(role any) string { return marker + fmt.Sprint(role) + "\x00" },
+	}
+}
+
+// render executes tmpl with data and splits the result into messages
+func render(tmpl *template.Template, data any) ([]provider.Message, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	marker := roleMarker + hex.EncodeToString(nonce) + ":"
+
+	tmpl, err := tmpl.Clone()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Funcs(roleFuncs(marker)).Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return splitMessages(buf.String(), marker)
+}
+
+// splitMessages turns a rendered prompt into messages at each marker. Text
+// without any markers becomes a single user message, unchanged.
+func splitMessages(text, marker string) ([]provider.Message, error) {
+	if !strings.Contains(text, marker) {
+		return []provider.Message{{Role: provider.RoleUser, Content: text}}, nil
+	}
+
+	parts := strings.Split(text, marker)
+	var messages []provider.Message
+
+	// Anything before the first marker is treated as user content
+	if content := strings.TrimSpace(parts[0]); content != "" {
+		messages = append(messages, provider.Message{Role: provider.RoleUser, Content: content})
+	}
+
+	for _, part := range parts[1:] {
+		name, content, _ := strings.Cut(part, "\x00")
+		role, err := parseRole(name)
+		if err != nil {
+			return nil, err
+		}
+		if content = strings.TrimSpace(content); content != "" {
+			messages = append(messages, provider.Message{Role: role, Content: content})
+		}
+	}
+
+	return messages, nil
+}
+
+// parseRole converts a role name from a template into a Role
+func parseRole(name string) (provider.Role, error) {
+	switch role := provider.Role(strings.ToLower(strings.TrimSpace(name))); role {
+	case provider.RoleSystem, provider.RoleUser, provider.RoleAssistant:
+		return role, nil
+	default:
+		return "", fmt.Errorf("unknown message role %q", name)
+	}
+}
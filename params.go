@@ -0,0 +1,54 @@
+package promptgen
+
+import (
+	"context"
+
+	"github.com/arjunsriva/promptgen/provider"
+)
+
+// Params controls how a completion is generated, e.g. temperature or model.
+// Unset fields fall back to the provider's configured defaults.
+type Params = provider.Params
+
+type paramsKey struct{}
+
+// ContextWithParams returns a context carrying per-call generation parameters.
+// They take precedence over parameters set on the generator:
+//
+//	ctx = promptgen.ContextWithParams(ctx, promptgen.Params{Temperature: provider.Float64(0)})
+//	result, err := generator.Run(ctx, input)
+func ContextWithParams(ctx context.Context, params Params) context.Context {
+	if existing, ok := ctx.Value(paramsKey{}).(Params); ok {
+		params = existing.Merge(params)
+	}
+	return context.WithValue(ctx, paramsKey{}, params)
+}
+
+// WithParams sets default generation parameters for every call
+func (g *Generator[I, O]) WithParams(params Params) *Generator[I, O] {
+	g.params = g.params.Merge(params)
+	return g
+}
+
+// WithModel overrides the provider's model for this generator
+func (g *Generator[I, O]) WithModel(model string) *Generator[I, O] {
+	return g.WithParams(Params{Model: model})
+}
+
+// WithTemperature sets the sampling temperature for this generator
+func (g *Generator[I, O]) WithTemperature(temperature float64) *Generator[I, O] {
+	return g.WithParams(Params{Temperature: provider.Float64(temperature)})
+}
+
+// WithMaxTokens limits the number of tokens generated per call
+func (g *Generator[I, O]) WithMaxTokens(maxTokens int) *Generator[I, O] {
+	return g.WithParams(Params{MaxTokens: maxTokens})
+}
+
+// callParams combines the generator's parameters with any set on the context
+func (g *Generator[I, O]) callParams(ctx context.Context) Params {
+	if params, ok := ctx.Value(paramsKey{}).(Params); ok {
+		return g.params.Merge(params)
+	}
+	return g.params
+}
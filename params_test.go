@@ -0,0 +1,55 @@
+package promptgen
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/arjunsriva/promptgen/provider"
+)
+
+func TestParams(t *testing.T) {
+	mock := &provider.MockProvider{Response: `{"response": "Hello"}`}
+
+	gen, _ := Create[TestInput, TestOutput]("Hello {{.Message}}")
+	gen.WithProvider(mock).
+		WithModel("gpt-4o-mini").
+		WithTemperature(0.2).
+		WithParams(Params{Stop: []string{"END"}})
+
+	t.Run("generator params", func(t *testing.T) {
+		if _, err := gen.Run(context.Background(), TestInput{Message: "test"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		got := mock.Requests[len(mock.Requests)-1].Params
+		want := Params{
+			Model:       "gpt-4o-mini",
+			Temperature: provider.Float64(0.2),
+			Stop:        []string{"END"},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("params = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("call params override generator params", func(t *testing.T) {
+		ctx := ContextWithParams(context.Background(), Params{Temperature: provider.Float64(0)})
+		ctx = ContextWithParams(ctx, Params{Seed: provider.Int(42)})
+
+		if _, err := gen.Run(ctx, TestInput{Message: "test"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		got := mock.Requests[len(mock.Requests)-1].Params
+		if got.Model != "gpt-4o-mini" {
+			t.Errorf("model = %q, want generator model", got.Model)
+		}
+		if got.Temperature == nil || *got.Temperature != 0 {
+			t.Errorf("temperature = %v, want 0", got.Temperature)
+		}
+		if got.Seed == nil || *got.Seed != 42 {
+			t.Errorf("seed = %v, want 42", got.Seed)
+		}
+	})
+}
@@ -20,11 +20,14 @@ package promptgen
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"text/template"
 	"time"
 
+	"github.com/arjunsriva/promptgen/cache"
 	"github.com/arjunsriva/promptgen/internal/handler"
 	jsonhandler "github.com/arjunsriva/promptgen/internal/json"
 	"github.com/arjunsriva/promptgen/internal/primitive"
@@ -37,13 +40,27 @@ type Generator[I any, O any] struct {
 	handler  handler.Handler[O]
 	provider provider.Provider
 	hooks    []Hook
-	timeout  time.Duration
+
+	streamHooks []StreamHook
+	timeout     time.Duration
+
+	repairAttempts int
+	retry          RetryPolicy
+	params         Params
+	pricing        provider.Pricing
+
+	tools         []ToolCaller
+	maxToolRounds int
+
+	cache cache.Cache
 }
 
-// Create initializes a new Generator with the given prompt template
+// Create initializes a new Generator with the given prompt template.
+// The template may split the prompt into system, user and assistant messages
+// using the {{system}}, {{user}}, {{assistant}} and {{role .Name}} functions.
 func Create[I any, O any](promptTemplate string) (*Generator[I, O], error) {
 	// Parse the template
-	tmpl, err := template.New("prompt").Parse(promptTemplate)
+	tmpl, err := template.New("prompt").Funcs(templateFuncs).Parse(promptTemplate)
 	if err != nil {
 		return nil, fmt.Errorf("invalid template: %w", err)
 	}
@@ -87,10 +104,18 @@ func (g *Generator[I, O]) ensureDefaultConfig() error {
 
 // Run executes the prompt with the given input and returns the validated output
 func (g *Generator[I, O]) Run(ctx context.Context, input I) (O, error) {
-	var output O
+	result, err := g.run(ctx, input)
+	return result.Output, err
+}
+
+// run executes the prompt and records metadata about every call it makes
+func (g *Generator[I, O]) run(ctx context.Context, input I) (*Result[O], error) {
+	result := &Result[O]{}
+	start := time.Now()
+	defer func() { result.Latency = time.Since(start) }()
 
 	if err := g.ensureDefaultConfig(); err != nil {
-		return output, &Error{
+		return result, &Error{
 			Err:     ErrConfiguration,
 			Message: err.Error(),
 			Code:    "config_error",
@@ -104,40 +129,130 @@ func (g *Generator[I, O]) Run(ctx context.Context, input I) (O, error) {
 		defer cancel()
 	}
 
+	req, err := g.request(ctx, input)
+	if err != nil {
+		return result, err
+	}
+
+	key := g.cacheKey(req)
+	if g.cached(ctx, key, result) {
+		return result, nil
+	}
+
+	req, response, err := g.completeWithTools(ctx, req, result)
+	if err != nil {
+		return result, err
+	}
+
+	var perr *Error
+	result.Output, perr = g.process(response.Content)
+	if perr == nil {
+		g.store(ctx, key, response)
+		return result, nil
+	}
+
+	// Ask the model to correct its own output until it passes or we run out of attempts
+	attempts := []RepairAttempt{{Attempt: 1, Response: response.Content, Error: perr.Message}}
+	for i := 0; i < g.repairAttempts; i++ {
+		req, response, err = g.completeWithTools(ctx, repairRequest(req, response.Content, perr.Message), result)
+		if err != nil {
+			return result, err
+		}
+
+		result.Output, perr = g.process(response.Content)
+		if perr == nil {
+			g.store(ctx, key, response)
+			return result, nil
+		}
+		attempts = append(attempts, RepairAttempt{Attempt: len(attempts) + 1, Response: response.Content, Error: perr.Message})
+	}
+
+	if g.repairAttempts > 0 {
+		perr.Details = map[string]interface{}{"attempts": attempts}
+	}
+	return result, perr
+}
+
+// request renders the template for input, wraps the final user message
+// with type-specific instructions and attaches the generation parameters,
+// tools and, for structured outputs, the response schema
+func (g *Generator[I, O]) request(ctx context.Context, input I) (provider.Request, error) {
 	// Execute template
-	var buf bytes.Buffer
-	if err := g.prompt.Execute(&buf, input); err != nil {
-		return output, fmt.Errorf("failed to execute template: %w", err)
+	messages, err := render(g.prompt, input)
+	if err != nil {
+		return provider.Request{}, fmt.Errorf("failed to execute template: %w", err)
 	}
 
-	// Wrap prompt with type-specific instructions
-	wrappedPrompt := g.handler.WrapPrompt(buf.String())
+	// Wrap prompt with type-specific instructions, leaving the response
+	// format to the provider when it can enforce it natively
+	wrap := g.handler.WrapPrompt
+	schemaHandler, structured := g.handler.(handler.SchemaHandler)
+	if caps := provider.CapabilitiesOf(g.provider); structured && (caps.JSONSchema || caps.JSONMode) {
+		wrap = schemaHandler.WrapPromptStructured
+	}
+	if last := len(messages) - 1; last >= 0 && messages[last].Role == provider.RoleUser {
+		messages[last].Content = wrap(messages[last].Content)
+	} else {
+		messages = append(messages, provider.Message{
+			Role:    provider.RoleUser,
+			Content: strings.TrimSpace(wrap("")),
+		})
+	}
 
-	// Run before hooks
+	req := provider.Request{Messages: messages, Params: g.callParams(ctx), Tools: g.toolDefinitions()}
+	if structured {
+		schema, err := schemaHandler.Schema()
+		if err != nil {
+			return provider.Request{}, fmt.Errorf("failed to generate schema: %w", err)
+		}
+		req.Schema = json.RawMessage(schema)
+	}
+	return req, nil
+}
+
+// applyBeforeHooks runs the before hooks once over the final user message
+// of a copy of req, leaving system and earlier conversation messages as the
+// template rendered them
+func (g *Generator[I, O]) applyBeforeHooks(ctx context.Context, req provider.Request) (provider.Request, error) {
+	messages := make([]provider.Message, len(req.Messages))
+	copy(messages, req.Messages)
+
+	last := len(messages) - 1
 	for _, hook := range g.hooks {
 		var err error
-		wrappedPrompt, err = hook.BeforeRequest(ctx, wrappedPrompt)
+		messages[last].Content, err = hook.BeforeRequest(ctx, messages[last].Content)
 		if err != nil {
-			return output, fmt.Errorf("hook error: %w", err)
+			return req, fmt.Errorf("hook error: %w", err)
 		}
 	}
 
-	// Call provider
-	response, err := g.provider.Complete(ctx, wrappedPrompt)
+	req.Messages = messages
+	return req, nil
+}
 
-	// Check for context/timeout errors first
+// complete sends a request through the hooks and provider and returns the raw response
+func (g *Generator[I, O]) complete(ctx context.Context, req provider.Request) (provider.Response, error) {
+	// Run before hooks
+	req, err := g.applyBeforeHooks(ctx, req)
 	if err != nil {
-		switch {
-		case errors.Is(err, context.DeadlineExceeded) || ctx.Err() == context.DeadlineExceeded:
-			return output, ErrTimeout
-		case errors.Is(err, context.Canceled):
-			return output, fmt.Errorf("request canceled: %w", err)
-		case errors.Is(err, provider.ErrRateLimit):
-			return output, ErrRateLimit
-		case errors.Is(err, provider.ErrContextLength):
-			return output, ErrContextLength
-		default:
-			return output, err
+		return provider.Response{}, err
+	}
+
+	// Call provider, retrying transient errors according to the policy
+	var response provider.Response
+	for attempt := 1; ; attempt++ {
+		var err error
+		response, err = g.provider.Complete(ctx, req)
+		if err == nil {
+			break
+		}
+
+		mapped := providerError(ctx, err)
+		if attempt >= g.retry.MaxAttempts || ctx.Err() != nil || !g.retry.shouldRetry(mapped) {
+			return provider.Response{}, mapped
+		}
+		if err := g.retry.sleep(ctx, g.retry.delay(attempt, err)); err != nil {
+			return provider.Response{}, providerError(ctx, err)
 		}
 	}
 
@@ -145,25 +260,52 @@ func (g *Generator[I, O]) Run(ctx context.Context, input I) (O, error) {
 	if ctx.Err() != nil {
 		switch ctx.Err() {
 		case context.DeadlineExceeded:
-			return output, ErrTimeout
+			return provider.Response{}, ErrTimeout
 		case context.Canceled:
-			return output, fmt.Errorf("request canceled: %w", ctx.Err())
+			return provider.Response{}, fmt.Errorf("request canceled: %w", ctx.Err())
 		default:
-			return output, ctx.Err()
+			return provider.Response{}, ctx.Err()
 		}
 	}
 
+	if response.Provider == "" {
+		response.Provider = provider.NameOf(g.provider)
+	}
+
 	// after response hooks
 	for _, hook := range g.hooks {
 		var err error
-		response, err = hook.AfterResponse(ctx, response, err)
+		response.Content, err = hook.AfterResponse(ctx, response.Content, err)
 		if err != nil {
-			return output, fmt.Errorf("hook error: %w", err)
+			return provider.Response{}, fmt.Errorf("hook error: %w", err)
 		}
 	}
 
+	return response, nil
+}
+
+// providerError maps a provider or context error to the package's error types
+func providerError(ctx context.Context, err error) error {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded) || ctx.Err() == context.DeadlineExceeded:
+		return ErrTimeout
+	case errors.Is(err, context.Canceled):
+		return fmt.Errorf("request canceled: %w", err)
+	case errors.Is(err, provider.ErrRateLimit):
+		return ErrRateLimit
+	case errors.Is(err, provider.ErrContextLength):
+		return ErrContextLength
+	case errors.Is(err, provider.ErrCircuitOpen):
+		return ErrCircuitOpen
+	default:
+		return err
+	}
+}
+
+// process parses and validates a raw response
+func (g *Generator[I, O]) process(response string) (O, *Error) {
 	// Parse response
-	output, err = g.handler.Parse(response)
+	output, err := g.handler.Parse(response)
 	if err != nil {
 		return output, &Error{
 			Err:     ErrInvalidResponse,
@@ -172,10 +314,24 @@ func (g *Generator[I, O]) Run(ctx context.Context, input I) (O, error) {
 		}
 	}
 
-	// Validate output
-	if err := g.handler.Validate(output); err != nil {
+	// Validate output, checking the response as sent when the handler can
+	// so that missing and unknown properties are caught
+	validate := func() error { return g.handler.Validate(output) }
+	if raw, ok := g.handler.(handler.RawValidator); ok {
+		validate = func() error { return raw.ValidateRaw(response) }
+	}
+	if err := validate(); err != nil {
 		return output, &Error{
-			Err:     ErrValidation,
+			Err:     fmt.Errorf("%w: %w", ErrValidation, err),
+			Message: err.Error(),
+			Code:    "validation_failed",
+		}
+	}
+
+	// Run the output type's own checks once the schema is satisfied
+	if err := validateOutput(&output); err != nil {
+		return output, &Error{
+			Err:     fmt.Errorf("%w: %w", ErrValidation, err),
 			Message: err.Error(),
 			Code:    "validation_failed",
 		}
@@ -184,15 +340,46 @@ func (g *Generator[I, O]) Run(ctx context.Context, input I) (O, error) {
 	return output, nil
 }
 
+// Validatable is implemented by output types with rules that JSON Schema
+// cannot express, such as constraints between fields. Validate is called
+// after schema validation, and an error fails the call with ErrValidation.
+type Validatable interface {
+	Validate() error
+}
+
+// validateOutput calls Validate on output if its type, or a pointer to it,
+// implements Validatable
+func validateOutput[O any](output *O) error {
+	if v, ok := any(*output).(Validatable); ok {
+		return v.Validate()
+	}
+	if v, ok := any(output).(Validatable); ok {
+		return v.Validate()
+	}
+	return nil
+}
+
 // WithProvider sets the AI provider to use
 func (g *Generator[I, O]) WithProvider(p provider.Provider) *Generator[I, O] {
 	g.provider = p
 	return g
 }
 
-// WithHook adds a hook to the generator
+// WithHook adds a hook to the generator. Hooks that also implement
+// StreamHook are used for streams as well; for other hooks, streams call
+// AfterResponse once on the full text when they end. Its result is what
+// Stream.Result parses, but chunks already delivered are not changed.
 func (g *Generator[I, O]) WithHook(hook Hook) *Generator[I, O] {
 	g.hooks = append(g.hooks, hook)
+	if streamHook, ok := hook.(StreamHook); ok {
+		g.streamHooks = append(g.streamHooks, streamHook)
+	}
+	return g
+}
+
+// WithStreamHook adds a hook that is only used for streams
+func (g *Generator[I, O]) WithStreamHook(hook StreamHook) *Generator[I, O] {
+	g.streamHooks = append(g.streamHooks, hook)
 	return g
 }
 
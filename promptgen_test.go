@@ -2,6 +2,7 @@ package promptgen
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"os"
 	"strings"
@@ -100,6 +101,69 @@ func TestGeneratorWithProvider(t *testing.T) {
 		}
 	})
 
+	t.Run("primitive responses", func(t *testing.T) {
+		mock := &provider.MockProvider{Response: "1 hour and 30 minutes"}
+		durGen, _ := Create[string, time.Duration]("How long is {{.}}?")
+		durGen.WithProvider(mock)
+		if d, err := durGen.Run(context.Background(), "the film"); err != nil || d != 90*time.Minute {
+			t.Errorf("Run() = %v, %v, want 1h30m", d, err)
+		}
+		if !strings.Contains(mock.Prompts[0], "1h30m") {
+			t.Errorf("expected duration instructions in the prompt, got %q", mock.Prompts[0])
+		}
+
+		mock.Response = "300"
+		byteGen, _ := Create[string, uint8]("Pick a number for {{.}}")
+		byteGen.WithProvider(mock)
+		if _, err := byteGen.Run(context.Background(), "a byte"); !errors.Is(err, ErrInvalidResponse) || !strings.Contains(err.Error(), "out of range for uint8") {
+			t.Errorf("expected a range error, got %v", err)
+		}
+	})
+
+	t.Run("response schema", func(t *testing.T) {
+		mock := &provider.MockProvider{Response: `{"response": "Hello world"}`}
+
+		gen, _ := Create[TestInput, TestOutput]("Hello {{.Message}}")
+		gen.WithProvider(mock)
+		if _, err := gen.Run(context.Background(), TestInput{Message: "test"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if schema := mock.Requests[0].Schema; !json.Valid(schema) || !strings.Contains(string(schema), `"response"`) {
+			t.Errorf("expected the output schema on the request, got %s", schema)
+		}
+
+		mock.Response = "Hello world"
+		strGen, _ := Create[string, string]("Say {{.}}")
+		strGen.WithProvider(mock)
+		if _, err := strGen.Run(context.Background(), "hello"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if schema := mock.Requests[1].Schema; schema != nil {
+			t.Errorf("string outputs should not carry a schema, got %s", schema)
+		}
+	})
+
+	t.Run("native structured output", func(t *testing.T) {
+		for _, supports := range []provider.Capabilities{{}, {JSONMode: true}, {JSONSchema: true, JSONMode: true}} {
+			mock := &provider.MockProvider{Response: `{"response": "Hello world"}`, Supports: supports}
+
+			gen, _ := Create[TestInput, TestOutput]("Hello {{.Message}}")
+			gen.WithProvider(mock)
+			if _, err := gen.Run(context.Background(), TestInput{Message: "test"}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			prompt := mock.Prompts[0]
+			native := supports.JSONMode || supports.JSONSchema
+			if strings.Contains(prompt, "triple backticks") == native {
+				t.Errorf("with %+v, code block instructions should only be used as a fallback:\n%s", supports, prompt)
+			}
+			if !strings.Contains(prompt, `"response"`) {
+				t.Errorf("with %+v, the prompt should still describe the schema:\n%s", supports, prompt)
+			}
+		}
+	})
+
 	t.Run("provider error", func(t *testing.T) {
 		mockProvider.Errors = []error{provider.ErrRateLimit}
 		gen, _ := Create[TestInput, TestOutput]("Hello {{.Message}}")
@@ -205,7 +269,7 @@ type MockProvider struct {
 	DelayMs  int
 }
 
-func (m *MockProvider) Complete(ctx context.Context, prompt string) (string, error) {
+func (m *MockProvider) Complete(ctx context.Context, req provider.Request) (provider.Response, error) {
 	if m.DelayMs > 0 {
 		time.Sleep(time.Duration(m.DelayMs) * time.Millisecond)
 	}
@@ -213,13 +277,13 @@ func (m *MockProvider) Complete(ctx context.Context, prompt string) (string, err
 	if len(m.Errors) > 0 {
 		err := m.Errors[0]
 		m.Errors = m.Errors[1:]
-		return "", err
+		return provider.Response{}, err
 	}
 
-	return m.Response, nil
+	return provider.Response{Content: m.Response}, nil
 }
 
-func (m *MockProvider) Stream(ctx context.Context, prompt string) (contentChan <-chan string, errChan <-chan error, err error) {
+func (m *MockProvider) Stream(ctx context.Context, req provider.Request) (contentChan <-chan string, errChan <-chan error, err error) {
 	content := make(chan string)
 	errs := make(chan error)
 
@@ -243,3 +307,87 @@ func (m *MockProvider) Stream(ctx context.Context, prompt string) (contentChan <
 
 	return content, errs, nil
 }
+
+// Booking validates a rule that JSON Schema cannot express
+type Booking struct {
+	Start time.Time `json:"start" jsonschema:"required"`
+	End   time.Time `json:"end" jsonschema:"required"`
+}
+
+func (b Booking) Validate() error {
+	if !b.End.After(b.Start) {
+		return errors.New("end must be after start")
+	}
+	return nil
+}
+
+// Decision validates with a pointer receiver
+type Decision struct {
+	Decision   string  `json:"decision" jsonschema:"required,enum=approve,enum=manual_review"`
+	Confidence float64 `json:"confidence" jsonschema:"minimum=0,maximum=1"`
+}
+
+func (d *Decision) Validate() error {
+	if d.Decision == "manual_review" && d.Confidence >= 0.5 {
+		return errors.New("confidence must be below 0.5 for manual_review")
+	}
+	return nil
+}
+
+func TestOutputValidate(t *testing.T) {
+	t.Run("value receiver", func(t *testing.T) {
+		mock := &provider.MockProvider{Response: `{"start": "2024-05-02T10:00:00Z", "end": "2024-05-01T10:00:00Z"}`}
+		gen, _ := Create[string, Booking]("Book {{.}}")
+		gen.WithProvider(mock)
+
+		_, err := gen.Run(context.Background(), "a meeting room")
+		var perr *Error
+		if !IsValidation(err) || !errors.As(err, &perr) || perr.Message != "end must be after start" {
+			t.Errorf("expected the Validate error, got %v", err)
+		}
+
+		mock.Response = `{"start": "2024-05-01T10:00:00Z", "end": "2024-05-01T11:00:00Z"}`
+		if _, err := gen.Run(context.Background(), "a meeting room"); err != nil {
+			t.Errorf("Run() error = %v", err)
+		}
+	})
+
+	t.Run("pointer receiver", func(t *testing.T) {
+		mock := &provider.MockProvider{Response: `{"decision": "manual_review", "confidence": 0.9}`}
+		gen, _ := Create[string, Decision]("Decide on {{.}}")
+		gen.WithProvider(mock)
+
+		if _, err := gen.Run(context.Background(), "claim 42"); !IsValidation(err) || !strings.Contains(err.Error(), "below 0.5") {
+			t.Errorf("expected the Validate error, got %v", err)
+		}
+	})
+
+	t.Run("after schema validation", func(t *testing.T) {
+		mock := &provider.MockProvider{Response: `{"decision": "reject", "confidence": 0.9}`}
+		gen, _ := Create[string, Decision]("Decide on {{.}}")
+		gen.WithProvider(mock)
+
+		_, err := gen.Run(context.Background(), "claim 42")
+		var verr *ValidationError
+		if !errors.As(err, &verr) {
+			t.Errorf("expected the schema violation to be reported first, got %v", err)
+		}
+	})
+
+	t.Run("repair", func(t *testing.T) {
+		mock := &provider.MockProvider{
+			Response:  `{"decision": "manual_review", "confidence": 0.2}`,
+			Responses: []provider.Response{{Content: `{"decision": "manual_review", "confidence": 0.9}`}},
+		}
+		gen, _ := Create[string, Decision]("Decide on {{.}}")
+		gen.WithProvider(mock).WithRepair(1)
+
+		output, err := gen.Run(context.Background(), "claim 42")
+		if err != nil || output.Confidence != 0.2 {
+			t.Fatalf("Run() = %+v, %v", output, err)
+		}
+		if !strings.Contains(mock.Prompts[1], "confidence must be below 0.5") {
+			t.Errorf("repair prompt should include the error, got %q", mock.Prompts[1])
+		}
+	})
+}
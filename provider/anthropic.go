@@ -0,0 +1,341 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+const (
+	anthropicBaseURL = "https://api.anthropic.com"
+	anthropicVersion = "2023-06-01"
+)
+
+// AnthropicConfig holds configuration for the Anthropic provider
+// A zero Temperature leaves the API's default in place; set
+// Params.Temperature to request a temperature of exactly 0.
+type AnthropicConfig struct {
+	APIKey      string
+	Model       string
+	Temperature float64
+	MaxTokens   int
+
+	// BaseURL defaults to https://api.anthropic.com
+	BaseURL string
+	// Version is sent as the anthropic-version header, defaults to 2023-06-01
+	Version string
+	// HTTPClient defaults to http.DefaultClient
+	HTTPClient *http.Client
+}
+
+// Anthropic implements the Provider interface using Anthropic's Messages API
+type Anthropic struct {
+	client *http.Client
+	config AnthropicConfig
+}
+
+// DefaultAnthropic creates a new Anthropic provider with default configuration
+func DefaultAnthropic() (*Anthropic, error) {
+	apiKey := os.Getenv("ANTHROPIC_API_KEY")
+	if apiKey == "" {
+		return nil, fmt.Errorf("ANTHROPIC_API_KEY environment variable is required")
+	}
+
+	return NewAnthropic(AnthropicConfig{
+		APIKey:      apiKey,
+		Model:       "claude-3-5-sonnet-latest",
+		Temperature: 0.7,
+		MaxTokens:   2000,
+	})
+}
+
+// NewAnthropic creates a new Anthropic provider with the given configuration
+func NewAnthropic(config AnthropicConfig) (*Anthropic, error) {
+	if config.APIKey == "" {
+		return nil, fmt.Errorf("API key is required")
+	}
+	if config.BaseURL == "" {
+		config.BaseURL = anthropicBaseURL
+	}
+	if config.Version == "" {
+		config.Version = anthropicVersion
+	}
+	if config.MaxTokens == 0 {
+		// The Messages API requires max_tokens
+		config.MaxTokens = 2000
+	}
+
+	client := config.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &Anthropic{
+		client: client,
+		config: config,
+	}, nil
+}
+
+// Name returns "anthropic"
+func (a *Anthropic) Name() string {
+	return "anthropic"
+}
+
+// Defaults returns the configured model, temperature and token limit
+func (a *Anthropic) Defaults() Params {
+	return Params{
+		Model:       a.config.Model,
+		Temperature: configFloat64(a.config.Temperature),
+		MaxTokens:   a.config.MaxTokens,
+	}
+}
+
+// anthropicMessage is a message in the Messages API format
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// anthropicRequest is the body of a Messages API request
+type anthropicRequest struct {
+	Model         string             `json:"model"`
+	MaxTokens     int                `json:"max_tokens"`
+	System        string             `json:"system,omitempty"`
+	Messages      []anthropicMessage `json:"messages"`
+	Temperature   *float64           `json:"temperature,omitempty"`
+	TopP          *float64           `json:"top_p,omitempty"`
+	StopSequences []string           `json:"stop_sequences,omitempty"`
+	Stream        bool               `json:"stream,omitempty"`
+}
+
+// anthropicUsage reports token counts in the Messages API format
+type anthropicUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// anthropicResponse is the body of a Messages API response
+type anthropicResponse struct {
+	Model   string `json:"model"`
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	StopReason string         `json:"stop_reason"`
+	Usage      anthropicUsage `json:"usage"`
+}
+
+// anthropicError is the error object returned by the API
+type anthropicError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// anthropicEvent is a streamed event; only the fields we use are decoded
+type anthropicEvent struct {
+	Type  string `json:"type"`
+	Delta struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"delta"`
+	Error anthropicError `json:"error"`
+}
+
+// Complete generates a completion for the given request using Anthropic's API
+func (a *Anthropic) Complete(ctx context.Context, req Request) (Response, error) {
+	body, err := a.messagesRequest(req, false)
+	if err != nil {
+		return Response{}, err
+	}
+	httpResp, err := a.do(ctx, body)
+	if err != nil {
+		return Response{}, err
+	}
+	defer httpResp.Body.Close()
+
+	var resp anthropicResponse
+	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+		return Response{}, fmt.Errorf("anthropic completion failed: invalid response: %w", err)
+	}
+
+	var content strings.Builder
+	for _, block := range resp.Content {
+		if block.Type == "text" {
+			content.WriteString(block.Text)
+		}
+	}
+
+	return Response{
+		Content:      content.String(),
+		Model:        resp.Model,
+		FinishReason: resp.StopReason,
+		Usage: Usage{
+			PromptTokens:     resp.Usage.InputTokens,
+			CompletionTokens: resp.Usage.OutputTokens,
+			TotalTokens:      resp.Usage.InputTokens + resp.Usage.OutputTokens,
+		},
+	}, nil
+}
+
+// Stream generates a completion and streams the response using Anthropic's API
+func (a *Anthropic) Stream(ctx context.Context, req Request) (contentChan <-chan string, errChan <-chan error, err error) {
+	body, err := a.messagesRequest(req, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	httpResp, err := a.do(ctx, body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content := make(chan string)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer httpResp.Body.Close()
+		defer close(content)
+		defer close(errs)
+
+		var streamErr error
+		readErr := readEvents(httpResp.Body, func(sse serverSentEvent) bool {
+			var event anthropicEvent
+			if err := json.Unmarshal([]byte(sse.Data), &event); err != nil {
+				streamErr = fmt.Errorf("stream receive failed: invalid event: %w", err)
+				return false
+			}
+
+			switch event.Type {
+			case "content_block_delta":
+				if event.Delta.Type != "text_delta" {
+					return true
+				}
+				select {
+				case content <- event.Delta.Text:
+					return true
+				case <-ctx.Done():
+					streamErr = ctx.Err()
+					return false
+				}
+			case "error":
+				streamErr = anthropicAPIError(0, event.Error)
+				return false
+			case "message_stop":
+				return false
+			default:
+				return true
+			}
+		})
+
+		switch {
+		case streamErr != nil:
+			errs <- streamErr
+		case readErr != nil:
+			errs <- fmt.Errorf("stream receive failed: %w", readErr)
+		}
+	}()
+
+	return content, errs, nil
+}
+
+// messagesRequest converts a Request into a Messages API request. Tool calls
+// are not supported yet, so requests offering tools fail rather than have the
+// model answer without them.
+func (a *Anthropic) messagesRequest(req Request, stream bool) (anthropicRequest, error) {
+	if len(req.Tools) > 0 {
+		return anthropicRequest{}, fmt.Errorf("anthropic provider does not support tools")
+	}
+
+	params := a.Defaults().Merge(req.Params)
+	if req.Params.TopP != nil && req.Params.Temperature == nil {
+		// The API rejects both on newer models, so a per-request top_p
+		// replaces the configured temperature
+		params.Temperature = nil
+	}
+
+	// System messages go in their own field rather than the message list
+	var system []string
+	messages := make([]anthropicMessage, 0, len(req.Messages))
+	for _, m := range req.Messages {
+		switch m.Role {
+		case RoleSystem:
+			system = append(system, m.Content)
+		case RoleAssistant:
+			messages = append(messages, anthropicMessage{Role: "assistant", Content: m.Content})
+		default:
+			messages = append(messages, anthropicMessage{Role: "user", Content: m.Content})
+		}
+	}
+
+	return anthropicRequest{
+		Model:         params.Model,
+		MaxTokens:     params.MaxTokens,
+		System:        strings.Join(system, "\n\n"),
+		Messages:      messages,
+		Temperature:   params.Temperature,
+		TopP:          params.TopP,
+		StopSequences: params.Stop,
+		Stream:        stream,
+	}, nil
+}
+
+// do sends a Messages API request and maps error responses
+func (a *Anthropic) do(ctx context.Context, body anthropicRequest) (*http.Response, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(a.config.BaseURL, "/")+"/v1/messages", bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("x-api-key", a.config.APIKey)
+	httpReq.Header.Set("anthropic-version", a.config.Version)
+
+	httpResp, err := a.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("anthropic request failed: %w", err)
+	}
+
+	if httpResp.StatusCode != http.StatusOK {
+		defer httpResp.Body.Close()
+
+		var body struct {
+			Error anthropicError `json:"error"`
+		}
+		if err := json.NewDecoder(httpResp.Body).Decode(&body); err != nil {
+			body.Error.Message = http.StatusText(httpResp.StatusCode)
+		}
+		return nil, withRetryAfter(anthropicAPIError(httpResp.StatusCode, body.Error), httpResp.Header)
+	}
+
+	return httpResp, nil
+}
+
+// anthropicAPIError maps an API error to the provider's error types
+func anthropicAPIError(status int, apiErr anthropicError) error {
+	err := fmt.Errorf("anthropic api error (%s): %s", apiErr.Type, apiErr.Message)
+	if status != 0 {
+		err = fmt.Errorf("anthropic api error (status %d, %s): %s", status, apiErr.Type, apiErr.Message)
+	}
+
+	switch {
+	case status == http.StatusTooManyRequests || status == 529,
+		apiErr.Type == "rate_limit_error" || apiErr.Type == "overloaded_error":
+		return fmt.Errorf("%w: %v", ErrRateLimit, err)
+	case status == http.StatusBadRequest || apiErr.Type == "invalid_request_error":
+		msg := strings.ToLower(apiErr.Message)
+		if strings.Contains(msg, "prompt is too long") || strings.Contains(msg, "context limit") ||
+			strings.Contains(msg, "context window") {
+			return fmt.Errorf("%w: %v", ErrContextLength, err)
+		}
+	case status >= 500 || apiErr.Type == "api_error":
+		return fmt.Errorf("%w: %v", ErrServer, err)
+	}
+	return err
+}
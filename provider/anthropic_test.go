@@ -0,0 +1,317 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newAnthropicTestServer starts a server that checks the request headers and
+// hands the decoded body to handle
+func newAnthropicTestServer(t *testing.T, handle func(w http.ResponseWriter, body anthropicRequest)) *Anthropic {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/messages" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		if r.Header.Get("x-api-key") != "test-key" {
+			t.Errorf("unexpected api key: %q", r.Header.Get("x-api-key"))
+		}
+		if r.Header.Get("anthropic-version") != anthropicVersion {
+			t.Errorf("unexpected version: %q", r.Header.Get("anthropic-version"))
+		}
+
+		var body anthropicRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("invalid request body: %v", err)
+		}
+		handle(w, body)
+	}))
+	t.Cleanup(server.Close)
+
+	provider, err := NewAnthropic(AnthropicConfig{
+		APIKey:      "test-key",
+		Model:       "claude-3-5-sonnet-latest",
+		Temperature: 0.7,
+		MaxTokens:   100,
+		BaseURL:     server.URL,
+	})
+	if err != nil {
+		t.Fatalf("NewAnthropic() error = %v", err)
+	}
+	return provider
+}
+
+// replaySSE writes a recorded event stream from testdata
+func replaySSE(t *testing.T, w http.ResponseWriter, name string) {
+	t.Helper()
+
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatalf("failed to read recording: %v", err)
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Write(data)
+}
+
+func TestAnthropicComplete(t *testing.T) {
+	provider := newAnthropicTestServer(t, func(w http.ResponseWriter, body anthropicRequest) {
+		if body.System != "Be brief." {
+			t.Errorf("system = %q, want %q", body.System, "Be brief.")
+		}
+		if len(body.Messages) != 2 || body.Messages[0].Role != "user" || body.Messages[1].Role != "assistant" {
+			t.Errorf("unexpected messages: %+v", body.Messages)
+		}
+		if body.Model != "claude-3-5-haiku-latest" || body.MaxTokens != 100 {
+			t.Errorf("unexpected model or max tokens: %q %d", body.Model, body.MaxTokens)
+		}
+		if body.Temperature == nil || *body.Temperature != 0 {
+			t.Errorf("temperature = %v, want 0", body.Temperature)
+		}
+		if body.Stream {
+			t.Error("stream should not be set")
+		}
+
+		w.Write([]byte(`{
+			"id": "msg_01",
+			"type": "message",
+			"role": "assistant",
+			"model": "claude-3-5-haiku-20241022",
+			"content": [{"type": "text", "text": "Hello"}, {"type": "text", "text": " world"}],
+			"stop_reason": "end_turn",
+			"usage": {"input_tokens": 12, "output_tokens": 3}
+		}`))
+	})
+
+	resp, err := provider.Complete(context.Background(), Request{
+		Messages: []Message{
+			{Role: RoleSystem, Content: "Be brief."},
+			{Role: RoleUser, Content: "Hi"},
+			{Role: RoleAssistant, Content: "Hello"},
+		},
+		Params: Params{Model: "claude-3-5-haiku-latest", Temperature: Float64(0)},
+	})
+	if err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+
+	if resp.Content != "Hello world" {
+		t.Errorf("content = %q, want 'Hello world'", resp.Content)
+	}
+	if resp.Model != "claude-3-5-haiku-20241022" || resp.FinishReason != "end_turn" {
+		t.Errorf("unexpected model or finish reason: %q %q", resp.Model, resp.FinishReason)
+	}
+	if resp.Usage != (Usage{PromptTokens: 12, CompletionTokens: 3, TotalTokens: 15}) {
+		t.Errorf("unexpected usage: %+v", resp.Usage)
+	}
+}
+
+func TestAnthropicSampling(t *testing.T) {
+	a := &Anthropic{config: AnthropicConfig{Model: "claude-3-5-haiku-latest", Temperature: 0.7, MaxTokens: 100}}
+
+	tests := []struct {
+		name     string
+		params   Params
+		wantTemp *float64
+		wantTopP *float64
+	}{
+		{name: "configured temperature", wantTemp: Float64(0.7)},
+		{name: "top p replaces configured temperature", params: Params{TopP: Float64(0.9)}, wantTopP: Float64(0.9)},
+		{name: "explicit temperature and top p", params: Params{Temperature: Float64(0.2), TopP: Float64(0.9)}, wantTemp: Float64(0.2), wantTopP: Float64(0.9)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := a.messagesRequest(Request{Messages: []Message{{Role: RoleUser, Content: "Hi"}}, Params: tt.params}, false)
+			if err != nil {
+				t.Fatalf("messagesRequest() error = %v", err)
+			}
+			if !equalFloat(body.Temperature, tt.wantTemp) || !equalFloat(body.TopP, tt.wantTopP) {
+				t.Errorf("temperature, top p = %v, %v, want %v, %v", body.Temperature, body.TopP, tt.wantTemp, tt.wantTopP)
+			}
+		})
+	}
+}
+
+// equalFloat compares two optional values
+func equalFloat(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func TestAnthropicTools(t *testing.T) {
+	provider := newAnthropicTestServer(t, func(w http.ResponseWriter, body anthropicRequest) {
+		t.Error("requests with tools should not be sent")
+	})
+
+	req := NewRequest("What's the weather in Paris?")
+	req.Tools = []Tool{{Name: "get_weather", Parameters: json.RawMessage(`{"type":"object"}`)}}
+
+	if _, err := provider.Complete(context.Background(), req); err == nil || !strings.Contains(err.Error(), "tools") {
+		t.Errorf("Complete() error = %v, want unsupported tools", err)
+	}
+	if _, _, err := provider.Stream(context.Background(), req); err == nil || !strings.Contains(err.Error(), "tools") {
+		t.Errorf("Stream() error = %v, want unsupported tools", err)
+	}
+}
+
+func TestAnthropicErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		retryAfter string
+		want       error
+		wantAfter  time.Duration
+	}{
+		{
+			name:       "rate limit",
+			status:     http.StatusTooManyRequests,
+			body:       `{"type":"error","error":{"type":"rate_limit_error","message":"Number of requests has exceeded your rate limit"}}`,
+			retryAfter: "12",
+			want:       ErrRateLimit,
+			wantAfter:  12 * time.Second,
+		},
+		{
+			name:   "overloaded",
+			status: 529,
+			body:   `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`,
+			want:   ErrRateLimit,
+		},
+		{
+			name:   "context length",
+			status: http.StatusBadRequest,
+			body:   `{"type":"error","error":{"type":"invalid_request_error","message":"prompt is too long: 201234 tokens > 200000 maximum"}}`,
+			want:   ErrContextLength,
+		},
+		{
+			name:   "server error",
+			status: http.StatusInternalServerError,
+			body:   `{"type":"error","error":{"type":"api_error","message":"Internal server error"}}`,
+			want:   ErrServer,
+		},
+		{
+			name:   "other bad request",
+			status: http.StatusBadRequest,
+			body:   `{"type":"error","error":{"type":"invalid_request_error","message":"max_tokens: Field required"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := newAnthropicTestServer(t, func(w http.ResponseWriter, _ anthropicRequest) {
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			})
+
+			_, err := provider.Complete(context.Background(), NewRequest("Hi"))
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("error = %v, want %v", err, tt.want)
+			}
+			if tt.want == nil && (errors.Is(err, ErrRateLimit) || errors.Is(err, ErrContextLength) || errors.Is(err, ErrServer)) {
+				t.Errorf("error should not be classified, got %v", err)
+			}
+			if after, ok := RetryAfter(err); ok != (tt.wantAfter > 0) || after != tt.wantAfter {
+				t.Errorf("RetryAfter() = %v, %v, want %v", after, ok, tt.wantAfter)
+			}
+
+			// Streams fail the same way before the first event
+			if _, _, err := provider.Stream(context.Background(), NewRequest("Hi")); tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("Stream() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnthropicStream(t *testing.T) {
+	t.Run("recorded stream", func(t *testing.T) {
+		provider := newAnthropicTestServer(t, func(w http.ResponseWriter, body anthropicRequest) {
+			if !body.Stream {
+				t.Error("stream should be set")
+			}
+			replaySSE(t, w, "anthropic_stream.sse")
+		})
+
+		content, errs, err := provider.Stream(context.Background(), NewRequest("Hi"))
+		if err != nil {
+			t.Fatalf("Stream() error = %v", err)
+		}
+
+		var chunks []string
+		for chunk := range content {
+			chunks = append(chunks, chunk)
+		}
+		if err := <-errs; err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if got := strings.Join(chunks, ""); got != `{"response": "Hello world"}` {
+			t.Errorf("streamed %q", got)
+		}
+		if len(chunks) != 3 {
+			t.Errorf("expected 3 chunks, got %d", len(chunks))
+		}
+	})
+
+	t.Run("overloaded mid-stream", func(t *testing.T) {
+		provider := newAnthropicTestServer(t, func(w http.ResponseWriter, _ anthropicRequest) {
+			replaySSE(t, w, "anthropic_stream_overloaded.sse")
+		})
+
+		content, errs, err := provider.Stream(context.Background(), NewRequest("Hi"))
+		if err != nil {
+			t.Fatalf("Stream() error = %v", err)
+		}
+
+		var chunks []string
+		for chunk := range content {
+			chunks = append(chunks, chunk)
+		}
+		if err := <-errs; !errors.Is(err, ErrRateLimit) {
+			t.Errorf("expected rate limit error, got %v", err)
+		}
+		if len(chunks) != 1 || chunks[0] != "Hel" {
+			t.Errorf("unexpected chunks: %v", chunks)
+		}
+	})
+}
+
+func TestReadEvents(t *testing.T) {
+	input := ": comment\nevent: one\ndata: first\ndata: line\n\ndata: {\"two\":2}\r\n\r\ndata: no trailing newline"
+
+	var events []serverSentEvent
+	if err := readEvents(strings.NewReader(input), func(e serverSentEvent) bool {
+		events = append(events, e)
+		return true
+	}); err != nil {
+		t.Fatalf("readEvents() error = %v", err)
+	}
+
+	want := []serverSentEvent{
+		{Event: "one", Data: "first\nline"},
+		{Data: `{"two":2}`},
+		{Data: "no trailing newline"},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d: %+v", len(events), len(want), events)
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Errorf("event %d = %+v, want %+v", i, events[i], want[i])
+		}
+	}
+}
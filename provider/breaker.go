@@ -0,0 +1,227 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// BreakerState is the state of a circuit breaker
+type BreakerState int
+
+const (
+	// BreakerClosed lets every request through
+	BreakerClosed BreakerState = iota
+	// BreakerOpen rejects every request with ErrCircuitOpen
+	BreakerOpen
+	// BreakerHalfOpen lets a limited number of trial requests through to
+	// decide whether to close or reopen the circuit
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("BreakerState(%d)", int(s))
+	}
+}
+
+// BreakerConfig configures a BreakerProvider. Zero values use the defaults.
+type BreakerConfig struct {
+	// FailureThreshold is the number of consecutive failures that opens the
+	// circuit. Defaults to 5.
+	FailureThreshold int
+	// OpenTimeout is how long the circuit stays open before trial requests
+	// are let through. Defaults to 30 seconds.
+	OpenTimeout time.Duration
+	// HalfOpenRequests is the number of trial requests let through while
+	// half-open; the circuit closes once all of them succeed. Defaults to 1.
+	HalfOpenRequests int
+	// IsFailure decides which errors count as failures. Defaults to
+	// IsUnavailable, so bad requests do not open the circuit.
+	IsFailure func(error) bool
+	// OnStateChange, if set, is called with the provider's name on every
+	// state transition
+	OnStateChange func(name string, from, to BreakerState)
+}
+
+// BreakerProvider is a circuit breaker in front of another provider. After
+// repeated failures it fails fast with ErrCircuitOpen instead of waiting on
+// a provider that is down, then lets trial requests through once OpenTimeout
+// has passed to find out whether it has recovered.
+type BreakerProvider struct {
+	provider Provider
+	config   BreakerConfig
+
+	mu        sync.Mutex
+	state     BreakerState
+	failures  int // consecutive failures while closed
+	trials    int // trial requests let through while half-open
+	successes int // successful trial requests while half-open
+	openedAt  time.Time
+
+	now func() time.Time
+}
+
+// CircuitBreaker wraps p in a circuit breaker
+func CircuitBreaker(p Provider, config BreakerConfig) *BreakerProvider {
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = 5
+	}
+	if config.OpenTimeout <= 0 {
+		config.OpenTimeout = 30 * time.Second
+	}
+	if config.HalfOpenRequests <= 0 {
+		config.HalfOpenRequests = 1
+	}
+	if config.IsFailure == nil {
+		config.IsFailure = IsUnavailable
+	}
+	return &BreakerProvider{provider: p, config: config, now: time.Now}
+}
+
+// Name returns the name of the wrapped provider
+func (b *BreakerProvider) Name() string {
+	return NameOf(b.provider)
+}
+
+// Defaults returns the default parameters of the wrapped provider
+func (b *BreakerProvider) Defaults() Params {
+	return DefaultsOf(b.provider)
+}
+
+// Capabilities returns the capabilities of the wrapped provider
+func (b *BreakerProvider) Capabilities() Capabilities {
+	return CapabilitiesOf(b.provider)
+}
+
+// State returns the current state of the circuit. An open circuit whose
+// timeout has passed reports BreakerOpen until the next request arrives.
+func (b *BreakerProvider) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// Complete completes the request unless the circuit is open
+func (b *BreakerProvider) Complete(ctx context.Context, req Request) (Response, error) {
+	if err := b.allow(); err != nil {
+		return Response{}, err
+	}
+
+	resp, err := b.provider.Complete(ctx, req)
+	b.record(ctx, err)
+	return resp, err
+}
+
+// Stream streams the request unless the circuit is open. The outcome is
+// recorded once the stream ends.
+func (b *BreakerProvider) Stream(ctx context.Context, req Request) (<-chan string, <-chan error, error) {
+	if err := b.allow(); err != nil {
+		return nil, nil, err
+	}
+
+	content, errs, err := b.provider.Stream(ctx, req)
+	if err != nil {
+		b.record(ctx, err)
+		return nil, nil, err
+	}
+
+	content, errs = relayStream(ctx, func(_ int, err error) { b.record(ctx, err) }, nil, content, errs)
+	return content, errs, nil
+}
+
+// allow decides whether a request may go through, moving an open circuit to
+// half-open once its timeout has passed
+func (b *BreakerProvider) allow() error {
+	b.mu.Lock()
+	var changed func()
+	defer func() {
+		b.mu.Unlock()
+		if changed != nil {
+			changed()
+		}
+	}()
+
+	if b.state == BreakerOpen {
+		wait := b.config.OpenTimeout - b.now().Sub(b.openedAt)
+		if wait > 0 {
+			return &RetryAfterError{Err: fmt.Errorf("%w: %s", ErrCircuitOpen, NameOf(b.provider)), After: wait}
+		}
+		changed = b.transition(BreakerHalfOpen)
+	}
+
+	if b.state == BreakerHalfOpen {
+		if b.trials >= b.config.HalfOpenRequests {
+			return fmt.Errorf("%w: %s", ErrCircuitOpen, NameOf(b.provider))
+		}
+		b.trials++
+	}
+	return nil
+}
+
+// record updates the circuit with the outcome of a request
+func (b *BreakerProvider) record(ctx context.Context, err error) {
+	// A caller giving up says nothing about the provider's health, but a
+	// deadline passing while waiting on the provider does
+	failed := err != nil && ctx.Err() != context.Canceled && b.config.IsFailure(err)
+
+	b.mu.Lock()
+	var changed func()
+	defer func() {
+		b.mu.Unlock()
+		if changed != nil {
+			changed()
+		}
+	}()
+
+	switch b.state {
+	case BreakerClosed:
+		switch {
+		case failed:
+			b.failures++
+			if b.failures >= b.config.FailureThreshold {
+				changed = b.transition(BreakerOpen)
+			}
+		case err == nil:
+			b.failures = 0
+		}
+	case BreakerHalfOpen:
+		switch {
+		case failed:
+			changed = b.transition(BreakerOpen)
+		case err == nil:
+			b.successes++
+			if b.successes >= b.config.HalfOpenRequests {
+				changed = b.transition(BreakerClosed)
+			}
+		default:
+			// Inconclusive, so let another trial request through instead
+			b.trials--
+		}
+	}
+}
+
+// transition moves the circuit to a new state and returns the callback
+// reporting it, to be called once the lock is released. Callers must hold mu.
+func (b *BreakerProvider) transition(to BreakerState) func() {
+	from := b.state
+	b.state = to
+	b.failures, b.trials, b.successes = 0, 0, 0
+	if to == BreakerOpen {
+		b.openedAt = b.now()
+	}
+
+	if b.config.OnStateChange == nil {
+		return nil
+	}
+	name := NameOf(b.provider)
+	return func() { b.config.OnStateChange(name, from, to) }
+}
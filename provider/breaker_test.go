@@ -0,0 +1,210 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestBreaker(p Provider, config BreakerConfig) (*BreakerProvider, *fakeTime, *[]string) {
+	var changes []string
+	config.OnStateChange = func(name string, from, to BreakerState) {
+		changes = append(changes, fmt.Sprintf("%s: %s -> %s", name, from, to))
+	}
+
+	clock := &fakeTime{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	b := CircuitBreaker(p, config)
+	b.now = clock.Now
+	return b, clock, &changes
+}
+
+func TestBreaker(t *testing.T) {
+	mock := &MockProvider{
+		Response: "ok",
+		Errors:   []error{ErrServer, ErrRateLimit, ErrServer},
+	}
+	b, clock, changes := newTestBreaker(mock, BreakerConfig{FailureThreshold: 3, OpenTimeout: time.Minute})
+
+	for i := 0; i < 3; i++ {
+		if _, err := b.Complete(context.Background(), NewRequest("Hi")); err == nil {
+			t.Fatalf("call %d: expected error", i)
+		}
+	}
+	if b.State() != BreakerOpen {
+		t.Fatalf("State() = %s, want open", b.State())
+	}
+
+	// While open, requests fail fast without reaching the provider
+	_, err := b.Complete(context.Background(), NewRequest("Hi"))
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("error = %v, want ErrCircuitOpen", err)
+	}
+	if after, ok := RetryAfter(err); !ok || after != time.Minute {
+		t.Errorf("RetryAfter() = %v, %v, want the rest of the open timeout", after, ok)
+	}
+	if len(mock.Requests) != 3 {
+		t.Errorf("expected 3 requests to reach the provider, got %d", len(mock.Requests))
+	}
+
+	// Once the timeout passes a trial request closes the circuit again
+	clock.After(time.Minute)
+	if _, err := b.Complete(context.Background(), NewRequest("Hi")); err != nil {
+		t.Fatalf("trial request error = %v", err)
+	}
+	if b.State() != BreakerClosed {
+		t.Errorf("State() = %s, want closed", b.State())
+	}
+
+	want := "mock: closed -> open, mock: open -> half-open, mock: half-open -> closed"
+	if got := strings.Join(*changes, ", "); got != want {
+		t.Errorf("state changes = %s, want %s", got, want)
+	}
+}
+
+func TestBreakerFailures(t *testing.T) {
+	t.Run("success resets the count", func(t *testing.T) {
+		mock := &MockProvider{Response: "ok", Errors: []error{ErrServer, ErrServer, nil, ErrServer, ErrServer}}
+		b, _, _ := newTestBreaker(mock, BreakerConfig{FailureThreshold: 3})
+
+		for i := 0; i < 5; i++ {
+			b.Complete(context.Background(), NewRequest("Hi"))
+		}
+		if b.State() != BreakerClosed {
+			t.Errorf("State() = %s, want closed", b.State())
+		}
+	})
+
+	t.Run("bad requests are not failures", func(t *testing.T) {
+		invalid := errors.New("invalid api key")
+		mock := &MockProvider{Errors: []error{invalid, invalid, ErrContextLength}}
+		b, _, _ := newTestBreaker(mock, BreakerConfig{FailureThreshold: 2})
+
+		for i := 0; i < 3; i++ {
+			b.Complete(context.Background(), NewRequest("Hi"))
+		}
+		if b.State() != BreakerClosed {
+			t.Errorf("State() = %s, want closed", b.State())
+		}
+	})
+
+	t.Run("custom classification", func(t *testing.T) {
+		mock := &MockProvider{Errors: []error{errors.New("invalid api key")}}
+		b, _, _ := newTestBreaker(mock, BreakerConfig{
+			FailureThreshold: 1,
+			IsFailure:        func(error) bool { return true },
+		})
+
+		b.Complete(context.Background(), NewRequest("Hi"))
+		if b.State() != BreakerOpen {
+			t.Errorf("State() = %s, want open", b.State())
+		}
+	})
+
+	t.Run("canceled requests are not failures", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		b, _, _ := newTestBreaker(&blockingProvider{}, BreakerConfig{FailureThreshold: 1})
+		b.Complete(ctx, NewRequest("Hi"))
+		if b.State() != BreakerClosed {
+			t.Errorf("State() = %s, want closed", b.State())
+		}
+	})
+}
+
+func TestBreakerHalfOpen(t *testing.T) {
+	t.Run("failed trial reopens", func(t *testing.T) {
+		mock := &MockProvider{Errors: []error{ErrServer, ErrServer}}
+		b, clock, changes := newTestBreaker(mock, BreakerConfig{FailureThreshold: 1, OpenTimeout: time.Minute})
+
+		b.Complete(context.Background(), NewRequest("Hi"))
+		clock.After(time.Minute)
+		if _, err := b.Complete(context.Background(), NewRequest("Hi")); !errors.Is(err, ErrServer) {
+			t.Fatalf("trial request error = %v, want ErrServer", err)
+		}
+		if b.State() != BreakerOpen {
+			t.Errorf("State() = %s, want open", b.State())
+		}
+		if _, err := b.Complete(context.Background(), NewRequest("Hi")); !errors.Is(err, ErrCircuitOpen) {
+			t.Errorf("error = %v, want ErrCircuitOpen for a full new timeout", err)
+		}
+		if len(*changes) != 3 {
+			t.Errorf("unexpected state changes: %v", *changes)
+		}
+	})
+
+	t.Run("limits trial requests", func(t *testing.T) {
+		p := &blockingProvider{release: make(chan struct{})}
+		b, clock, _ := newTestBreaker(p, BreakerConfig{FailureThreshold: 1, OpenTimeout: time.Minute})
+		b.record(context.Background(), ErrServer)
+		clock.After(time.Minute)
+
+		done := make(chan error)
+		go func() {
+			_, err := b.Complete(context.Background(), NewRequest("Hi"))
+			done <- err
+		}()
+
+		// Wait for the trial request to reach the provider
+		for {
+			p.mu.Lock()
+			active := p.active
+			p.mu.Unlock()
+			if active == 1 {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+
+		if _, err := b.Complete(context.Background(), NewRequest("Hi")); !errors.Is(err, ErrCircuitOpen) {
+			t.Errorf("second trial error = %v, want ErrCircuitOpen", err)
+		}
+
+		p.release <- struct{}{}
+		if err := <-done; err != nil {
+			t.Fatalf("trial request error = %v", err)
+		}
+		if b.State() != BreakerClosed {
+			t.Errorf("State() = %s, want closed", b.State())
+		}
+	})
+}
+
+func TestBreakerStream(t *testing.T) {
+	b, _, _ := newTestBreaker(partialStream{chunk: "Hel", err: ErrServer}, BreakerConfig{FailureThreshold: 1})
+
+	content, errs, err := b.Stream(context.Background(), NewRequest("Hi"))
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+	if _, err := collect(content, errs); !errors.Is(err, ErrServer) {
+		t.Errorf("collect() error = %v, want ErrServer", err)
+	}
+
+	// A stream failing partway through counts once it has ended
+	if b.State() != BreakerOpen {
+		t.Errorf("State() = %s, want open", b.State())
+	}
+	if _, _, err := b.Stream(context.Background(), NewRequest("Hi")); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("error = %v, want ErrCircuitOpen", err)
+	}
+}
+
+func TestFallbackOnOpenCircuit(t *testing.T) {
+	primary := CircuitBreaker(namedMock{&MockProvider{Errors: []error{ErrServer}}, "primary"}, BreakerConfig{FailureThreshold: 1})
+	backup := namedMock{&MockProvider{Response: "from backup"}, "backup"}
+	p := Fallback(primary, backup)
+
+	for i := 0; i < 2; i++ {
+		resp, err := p.Complete(context.Background(), NewRequest("Hi"))
+		if err != nil || resp.Provider != "backup" {
+			t.Errorf("Complete() = %+v, %v", resp, err)
+		}
+	}
+	if len(primary.provider.(namedMock).Requests) != 1 {
+		t.Error("the open circuit should skip the primary")
+	}
+}
@@ -0,0 +1,233 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+)
+
+// FallbackProvider sends requests to a primary provider and fails over to
+// secondary providers, in order, when it is rate limited or unavailable
+type FallbackProvider struct {
+	providers []Provider
+
+	// FallbackOnContextLength also fails over on ErrContextLength, e.g. to a
+	// model with a larger context window
+	FallbackOnContextLength bool
+
+	// OnFallback, if set, is called with the name of each provider that
+	// failed and the error it returned before the next one is tried
+	OnFallback func(name string, err error)
+
+	// OnAnswer, if set, is called with the name of the provider that
+	// answered. Streams call it once they are committed to a provider,
+	// since they have no Response to record it in.
+	OnAnswer func(name string)
+}
+
+// Fallback creates a provider that tries primary first and then each of
+// secondaries in turn. It fails over on rate limits, timeouts, connection
+// failures and server errors. The provider that answered is recorded in
+// Response.Provider.
+func Fallback(primary Provider, secondaries ...Provider) *FallbackProvider {
+	return &FallbackProvider{providers: append([]Provider{primary}, secondaries...)}
+}
+
+// Name describes the chain, e.g. "fallback(openai,anthropic)"
+func (f *FallbackProvider) Name() string {
+	names := make([]string, len(f.providers))
+	for i, p := range f.providers {
+		names[i] = NameOf(p)
+	}
+	return "fallback(" + strings.Join(names, ",") + ")"
+}
+
+// Defaults returns the default parameters of the primary provider
+func (f *FallbackProvider) Defaults() Params {
+	return DefaultsOf(f.providers[0])
+}
+
+// Capabilities reports the features supported by every provider in the chain
+func (f *FallbackProvider) Capabilities() Capabilities {
+	caps := CapabilitiesOf(f.providers[0])
+	for _, p := range f.providers[1:] {
+		other := CapabilitiesOf(p)
+		caps.JSONSchema = caps.JSONSchema && other.JSONSchema
+		caps.JSONMode = caps.JSONMode && other.JSONMode
+	}
+	return caps
+}
+
+// Complete generates a completion with the first provider that succeeds
+func (f *FallbackProvider) Complete(ctx context.Context, req Request) (Response, error) {
+	var err error
+	for i, p := range f.providers {
+		var resp Response
+		resp, err = p.Complete(ctx, req)
+		if err == nil {
+			if resp.Provider == "" {
+				resp.Provider = NameOf(p)
+			}
+			f.answer(resp.Provider)
+			return resp, nil
+		}
+		if i == len(f.providers)-1 || !f.shouldFallback(ctx, err) {
+			break
+		}
+		f.fallback(p, err)
+	}
+	return Response{}, err
+}
+
+// Stream streams a completion from the first provider that produces output.
+// A provider that fails before its first chunk of content is skipped; once
+// one has been received the stream is committed to that provider. Empty
+// chunks, like the role delta OpenAI sends first, do not commit the stream.
+func (f *FallbackProvider) Stream(ctx context.Context, req Request) (<-chan string, <-chan error, error) {
+	var err error
+	for i, p := range f.providers {
+		last := i == len(f.providers)-1
+
+		attemptCtx, cancel := context.WithCancel(ctx)
+		content, errs, streamErr := p.Stream(attemptCtx, req)
+		if streamErr == nil {
+			var read []string
+			read, streamErr = firstChunk(attemptCtx, content, errs)
+			if streamErr == nil {
+				f.answer(NameOf(p))
+				content, errs := relayStream(ctx, func(int, error) { cancel() }, read, content, errs)
+				return content, errs, nil
+			}
+		}
+		cancel()
+
+		err = streamErr
+		if last || !f.shouldFallback(ctx, err) {
+			break
+		}
+		f.fallback(p, err)
+	}
+	return nil, nil, err
+}
+
+// shouldFallback reports whether err should be retried with the next provider
+func (f *FallbackProvider) shouldFallback(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		// The caller gave up, so there is no point in trying another provider
+		return false
+	}
+	if errors.Is(err, ErrContextLength) {
+		return f.FallbackOnContextLength
+	}
+	return errors.Is(err, ErrCircuitOpen) || IsUnavailable(err)
+}
+
+// IsUnavailable reports whether err indicates that a provider is overloaded
+// or unreachable rather than that the request itself was bad: rate limits,
+// server errors, timeouts and connection failures
+func IsUnavailable(err error) bool {
+	var opErr *net.OpError
+	var netErr net.Error
+	switch {
+	case errors.Is(err, ErrRateLimit), errors.Is(err, ErrServer):
+		return true
+	case errors.Is(err, context.DeadlineExceeded):
+		return true
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return true
+	case errors.As(err, &opErr):
+		// Connection refused, DNS failures and the like
+		return true
+	}
+	return false
+}
+
+// answer reports the provider that answered
+func (f *FallbackProvider) answer(name string) {
+	if f.OnAnswer != nil {
+		f.OnAnswer(name)
+	}
+}
+
+// fallback reports a failed provider
+func (f *FallbackProvider) fallback(p Provider, err error) {
+	if f.OnFallback != nil {
+		f.OnFallback(NameOf(p), err)
+	}
+}
+
+// firstChunk waits for the first non-empty chunk of a stream and returns it
+// along with the empty chunks before it, or just those if the stream ended
+// without producing any content
+func firstChunk(ctx context.Context, content <-chan string, errs <-chan error) ([]string, error) {
+	var read []string
+	for content != nil || errs != nil {
+		select {
+		case chunk, ok := <-content:
+			if !ok {
+				content = nil
+				continue
+			}
+			read = append(read, chunk)
+			if chunk != "" {
+				return read, nil
+			}
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return read, nil
+}
+
+// relayStream relays a stream, starting with any chunks already read from
+// it, and calls done with the number of bytes relayed and its final error
+// once it ends
+func relayStream(ctx context.Context, done func(sent int, err error), read []string, content <-chan string, errs <-chan error) (<-chan string, <-chan error) {
+	out := make(chan string)
+	outErrs := make(chan error, 1)
+
+	go func() {
+		var sent int
+		var err error
+		defer close(out)
+		defer close(outErrs)
+		defer func() { done(sent, err) }()
+
+		send := func(chunk string) bool {
+			select {
+			case out <- chunk:
+				sent += len(chunk)
+				return true
+			case <-ctx.Done():
+				err = ctx.Err()
+				outErrs <- err
+				return false
+			}
+		}
+
+		for _, chunk := range read {
+			if !send(chunk) {
+				return
+			}
+		}
+		for chunk := range content {
+			if !send(chunk) {
+				return
+			}
+		}
+		if err = <-errs; err != nil {
+			outErrs <- err
+		}
+	}()
+
+	return out, outErrs
+}
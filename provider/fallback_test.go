@@ -0,0 +1,263 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+)
+
+// namedMock is a mock provider with its own name
+type namedMock struct {
+	*MockProvider
+	name string
+}
+
+func (n namedMock) Name() string {
+	return n.name
+}
+
+// partialStream streams one chunk and then fails
+type partialStream struct {
+	chunk string
+	err   error
+}
+
+func (p partialStream) Complete(context.Context, Request) (Response, error) {
+	return Response{}, p.err
+}
+
+func (p partialStream) Stream(context.Context, Request) (<-chan string, <-chan error, error) {
+	content := make(chan string)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(content)
+		defer close(errs)
+
+		content <- p.chunk
+		errs <- p.err
+	}()
+
+	return content, errs, nil
+}
+
+func TestFallbackComplete(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           error
+		contextLength bool
+		wantFallback  bool
+	}{
+		{name: "rate limit", err: ErrRateLimit, wantFallback: true},
+		{name: "server error", err: ErrServer, wantFallback: true},
+		{name: "timeout", err: context.DeadlineExceeded, wantFallback: true},
+		{name: "connection refused", err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}, wantFallback: true},
+		{name: "context length", err: ErrContextLength},
+		{name: "context length enabled", err: ErrContextLength, contextLength: true, wantFallback: true},
+		{name: "other error", err: errors.New("invalid api key")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary := namedMock{&MockProvider{Errors: []error{tt.err}}, "primary"}
+			backup := namedMock{&MockProvider{Response: "from backup"}, "backup"}
+
+			var failed []string
+			p := Fallback(primary, backup)
+			p.FallbackOnContextLength = tt.contextLength
+			p.OnFallback = func(name string, err error) {
+				failed = append(failed, name)
+			}
+
+			resp, err := p.Complete(context.Background(), NewRequest("Hi"))
+			if !tt.wantFallback {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("error = %v, want %v", err, tt.err)
+				}
+				if len(backup.Requests) != 0 {
+					t.Error("backup should not be called")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Complete() error = %v", err)
+			}
+			if resp.Content != "from backup" || resp.Provider != "backup" {
+				t.Errorf("unexpected response: %+v", resp)
+			}
+			if len(failed) != 1 || failed[0] != "primary" {
+				t.Errorf("OnFallback calls = %v, want [primary]", failed)
+			}
+		})
+	}
+
+	t.Run("primary answers", func(t *testing.T) {
+		p := Fallback(namedMock{&MockProvider{Response: "ok"}, "primary"}, &MockProvider{})
+		resp, err := p.Complete(context.Background(), NewRequest("Hi"))
+		if err != nil || resp.Provider != "primary" {
+			t.Errorf("Complete() = %+v, %v", resp, err)
+		}
+	})
+
+	t.Run("failure after empty first chunk", func(t *testing.T) {
+		backup := namedMock{&MockProvider{StreamTokens: []string{"", "Hello"}}, "backup"}
+
+		chain := Fallback(partialStream{chunk: "", err: ErrServer}, backup)
+		var answered []string
+		chain.OnAnswer = func(name string) { answered = append(answered, name) }
+
+		content, errs, err := chain.Stream(context.Background(), NewRequest("Hi"))
+		if err != nil {
+			t.Fatalf("Stream() error = %v", err)
+		}
+
+		chunks, err := collect(content, errs)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if len(chunks) != 2 || chunks[0] != "" || chunks[1] != "Hello" {
+			t.Errorf("chunks = %q, want the backup's chunks including the empty one", chunks)
+		}
+		if len(answered) != 1 || answered[0] != "backup" {
+			t.Errorf("OnAnswer got %v, want [backup]", answered)
+		}
+	})
+
+	t.Run("all fail", func(t *testing.T) {
+		p := Fallback(
+			&MockProvider{Errors: []error{ErrRateLimit}},
+			&MockProvider{Errors: []error{ErrServer}},
+		)
+		if _, err := p.Complete(context.Background(), NewRequest("Hi")); !errors.Is(err, ErrServer) {
+			t.Errorf("error = %v, want the last provider's error", err)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		backup := &MockProvider{Response: "from backup"}
+		p := Fallback(&MockProvider{Errors: []error{ErrRateLimit}}, backup)
+		if _, err := p.Complete(ctx, NewRequest("Hi")); err == nil {
+			t.Error("expected error")
+		}
+		if len(backup.Requests) != 0 {
+			t.Error("backup should not be called once the caller has given up")
+		}
+	})
+}
+
+func TestFallbackStream(t *testing.T) {
+	t.Run("failure before first chunk", func(t *testing.T) {
+		primary := &MockProvider{Errors: []error{ErrRateLimit}}
+		backup := namedMock{&MockProvider{StreamTokens: []string{"Hello", " world"}}, "backup"}
+
+		chain := Fallback(primary, backup)
+		var answered []string
+		chain.OnAnswer = func(name string) { answered = append(answered, name) }
+
+		content, errs, err := chain.Stream(context.Background(), NewRequest("Hi"))
+		if err != nil {
+			t.Fatalf("Stream() error = %v", err)
+		}
+
+		chunks, err := collect(content, errs)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if got := strings.Join(chunks, ""); got != "Hello world" {
+			t.Errorf("streamed %q", got)
+		}
+		if len(answered) != 1 || answered[0] != "backup" {
+			t.Errorf("OnAnswer got %v, want [backup]", answered)
+		}
+	})
+
+	t.Run("failure after first chunk", func(t *testing.T) {
+		backup := &MockProvider{StreamTokens: []string{"unused"}}
+
+		content, errs, err := Fallback(partialStream{chunk: "Hel", err: ErrServer}, backup).Stream(context.Background(), NewRequest("Hi"))
+		if err != nil {
+			t.Fatalf("Stream() error = %v", err)
+		}
+
+		chunks, err := collect(content, errs)
+		if !errors.Is(err, ErrServer) {
+			t.Errorf("expected the committed provider's error, got %v", err)
+		}
+		if len(chunks) != 1 || chunks[0] != "Hel" {
+			t.Errorf("unexpected chunks: %v", chunks)
+		}
+		if len(backup.Requests) != 0 {
+			t.Error("backup should not be called once the stream has started")
+		}
+	})
+
+	t.Run("failure after empty first chunk", func(t *testing.T) {
+		backup := namedMock{&MockProvider{StreamTokens: []string{"", "Hello"}}, "backup"}
+
+		chain := Fallback(partialStream{chunk: "", err: ErrServer}, backup)
+		var answered []string
+		chain.OnAnswer = func(name string) { answered = append(answered, name) }
+
+		content, errs, err := chain.Stream(context.Background(), NewRequest("Hi"))
+		if err != nil {
+			t.Fatalf("Stream() error = %v", err)
+		}
+
+		chunks, err := collect(content, errs)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if len(chunks) != 2 || chunks[0] != "" || chunks[1] != "Hello" {
+			t.Errorf("chunks = %q, want the backup's chunks including the empty one", chunks)
+		}
+		if len(answered) != 1 || answered[0] != "backup" {
+			t.Errorf("OnAnswer got %v, want [backup]", answered)
+		}
+	})
+
+	t.Run("all fail", func(t *testing.T) {
+		p := Fallback(
+			&MockProvider{Errors: []error{ErrRateLimit}},
+			&MockProvider{Errors: []error{ErrContextLength}},
+		)
+		if _, _, err := p.Stream(context.Background(), NewRequest("Hi")); !errors.Is(err, ErrContextLength) {
+			t.Errorf("error = %v, want the last provider's error", err)
+		}
+	})
+
+	t.Run("empty stream", func(t *testing.T) {
+		content, errs, err := Fallback(&MockProvider{}, &MockProvider{}).Stream(context.Background(), NewRequest("Hi"))
+		if err != nil {
+			t.Fatalf("Stream() error = %v", err)
+		}
+		if chunks, err := collect(content, errs); len(chunks) != 0 || err != nil {
+			t.Errorf("collect() = %v, %v", chunks, err)
+		}
+	})
+}
+
+func TestFallbackName(t *testing.T) {
+	p := Fallback(namedMock{&MockProvider{}, "openai"}, &MockProvider{})
+	if got := p.Name(); got != "fallback(openai,mock)" {
+		t.Errorf("Name() = %q", got)
+	}
+	if got := NameOf(p); got != p.Name() {
+		t.Errorf("NameOf() = %q, want the chain's name", got)
+	}
+}
+
+func TestFallbackCapabilities(t *testing.T) {
+	p := Fallback(
+		&MockProvider{Supports: Capabilities{JSONSchema: true, JSONMode: true}},
+		&MockProvider{Supports: Capabilities{JSONMode: true}},
+	)
+	if got, want := p.Capabilities(), (Capabilities{JSONMode: true}); got != want {
+		t.Errorf("Capabilities() = %+v, want %+v", got, want)
+	}
+}
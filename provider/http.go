@@ -0,0 +1,86 @@
+package provider
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(header http.Header) (time.Duration, bool) {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0), true
+	}
+	return 0, false
+}
+
+// withRetryAfter attaches the response's Retry-After hint to err, if present
+func withRetryAfter(err error, header http.Header) error {
+	if after, ok := parseRetryAfter(header); ok {
+		return &RetryAfterError{Err: err, After: after}
+	}
+	return err
+}
+
+// serverSentEvent is a single event from a text/event-stream response
+type serverSentEvent struct {
+	Event string
+	Data  string
+}
+
+// readEvents reads server-sent events from r, calling fn for each one until
+// fn returns false or the stream ends
+func readEvents(r io.Reader, fn func(serverSentEvent) bool) error {
+	reader := bufio.NewReader(r)
+	var event serverSentEvent
+	var data []string
+
+	// dispatch delivers the pending event, reporting whether to continue
+	dispatch := func() bool {
+		if len(data) == 0 {
+			return true
+		}
+		event.Data = strings.Join(data, "\n")
+		next := fn(event)
+		event, data = serverSentEvent{}, nil
+		return next
+	}
+
+	for {
+		raw, err := reader.ReadString('\n')
+		if raw != "" {
+			line := strings.TrimRight(raw, "\r\n")
+			switch {
+			case line == "":
+				// A blank line ends the event
+				if !dispatch() {
+					return nil
+				}
+			case strings.HasPrefix(line, ":"):
+				// Comment
+			case strings.HasPrefix(line, "event:"):
+				event.Event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+			case strings.HasPrefix(line, "data:"):
+				data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+			}
+		}
+
+		if err == io.EOF {
+			dispatch()
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package provider
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limits configures a LimitedProvider. Zero values disable a limit.
+type Limits struct {
+	RequestsPerMinute int
+	// TokensPerMinute limits prompt and completion tokens. Requests are
+	// admitted using an estimate from the prompt length and MaxTokens, which
+	// is corrected once the request ends: with the usage the provider
+	// reports, or else an estimate from the response text. Requests that
+	// fail without a response are refunded.
+	TokensPerMinute int
+	MaxInFlight     int
+}
+
+// LimitedProvider enforces client-side rate and concurrency limits in front
+// of another provider. Callers wait their turn until the limits allow the
+// request or their context is done. Share one LimitedProvider between
+// generators to share its limits.
+type LimitedProvider struct {
+	provider Provider
+	limits   Limits
+	inFlight chan struct{}
+
+	mu       sync.Mutex
+	requests float64 // requests available now
+	tokens   float64 // tokens available now
+	updated  time.Time
+
+	now   func() time.Time
+	after func(time.Duration) <-chan time.Time
+}
+
+// Limited wraps p so that requests stay within limits
+func Limited(p Provider, limits Limits) *LimitedProvider {
+	l := &LimitedProvider{
+		provider: p,
+		limits:   limits,
+		requests: float64(limits.RequestsPerMinute),
+		tokens:   float64(limits.TokensPerMinute),
+		now:      time.Now,
+		after:    time.After,
+	}
+	if limits.MaxInFlight > 0 {
+		l.inFlight = make(chan struct{}, limits.MaxInFlight)
+	}
+	l.updated = l.now()
+	return l
+}
+
+// Name returns the name of the wrapped provider
+func (l *LimitedProvider) Name() string {
+	return NameOf(l.provider)
+}
+
+// Defaults returns the default parameters of the wrapped provider
+func (l *LimitedProvider) Defaults() Params {
+	return DefaultsOf(l.provider)
+}
+
+// Capabilities returns the capabilities of the wrapped provider
+func (l *LimitedProvider) Capabilities() Capabilities {
+	return CapabilitiesOf(l.provider)
+}
+
+// Complete waits for the limits to allow the request and then completes it
+func (l *LimitedProvider) Complete(ctx context.Context, req Request) (Response, error) {
+	charged, err := l.acquire(ctx, l.estimateTokens(req))
+	if err != nil {
+		return Response{}, err
+	}
+	defer l.release()
+
+	resp, err := l.provider.Complete(ctx, req)
+	switch {
+	case err != nil:
+		l.settle(charged, 0)
+	case resp.Usage.TotalTokens > 0:
+		l.settle(charged, resp.Usage.TotalTokens)
+	default:
+		l.settle(charged, promptTokens(req)+textTokens(len(resp.Content)))
+	}
+	return resp, err
+}
+
+// Stream waits for the limits to allow the request and then streams it. The
+// request counts as in flight until the stream ends, when its tokens are
+// settled using an estimate from the streamed text.
+func (l *LimitedProvider) Stream(ctx context.Context, req Request) (<-chan string, <-chan error, error) {
+	charged, err := l.acquire(ctx, l.estimateTokens(req))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content, errs, err := l.provider.Stream(ctx, req)
+	if err != nil {
+		l.settle(charged, 0)
+		l.release()
+		return nil, nil, err
+	}
+
+	content, errs = relayStream(ctx, func(sent int, _ error) {
+		l.settle(charged, promptTokens(req)+textTokens(sent))
+		l.release()
+	}, nil, content, errs)
+	return content, errs, nil
+}
+
+// acquire waits for an in-flight slot and for enough request and token
+// budget, returning the tokens charged for the request
+func (l *LimitedProvider) acquire(ctx context.Context, tokens int) (int, error) {
+	if l.inFlight != nil {
+		select {
+		case l.inFlight <- struct{}{}:
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+
+	// A request larger than the whole budget only waits for a full budget
+	if tpm := l.limits.TokensPerMinute; tpm > 0 {
+		tokens = min(tokens, tpm)
+	}
+
+	for {
+		wait := l.take(tokens)
+		if wait == 0 {
+			return tokens, nil
+		}
+
+		select {
+		case <-l.after(wait):
+		case <-ctx.Done():
+			l.release()
+			return 0, ctx.Err()
+		}
+	}
+}
+
+// release frees an in-flight slot
+func (l *LimitedProvider) release() {
+	if l.inFlight != nil {
+		<-l.inFlight
+	}
+}
+
+// take spends the budget for one request, or reports how long to wait until
+// enough budget is available
+func (l *LimitedProvider) take(tokens int) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Refill both budgets for the time since the last update
+	now := l.now()
+	minutes := now.Sub(l.updated).Minutes()
+	l.updated = now
+	if rpm := float64(l.limits.RequestsPerMinute); rpm > 0 {
+		l.requests = min(rpm, l.requests+minutes*rpm)
+	}
+	if tpm := float64(l.limits.TokensPerMinute); tpm > 0 {
+		l.tokens = min(tpm, l.tokens+minutes*tpm)
+	}
+
+	cost := float64(tokens)
+	var wait time.Duration
+	if rpm := float64(l.limits.RequestsPerMinute); rpm > 0 && l.requests < 1 {
+		wait = max(wait, minutesToDuration((1-l.requests)/rpm))
+	}
+	if tpm := float64(l.limits.TokensPerMinute); tpm > 0 && l.tokens < cost {
+		wait = max(wait, minutesToDuration((cost-l.tokens)/tpm))
+	}
+	if wait > 0 {
+		return wait
+	}
+
+	if l.limits.RequestsPerMinute > 0 {
+		l.requests--
+	}
+	if l.limits.TokensPerMinute > 0 {
+		l.tokens -= cost
+	}
+	return 0
+}
+
+// settle corrects the token budget for a request once its actual usage is
+// known, given the tokens charged when it was admitted
+func (l *LimitedProvider) settle(charged, actual int) {
+	if l.limits.TokensPerMinute == 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens = min(float64(l.limits.TokensPerMinute), l.tokens-float64(actual-charged))
+}
+
+// minutesToDuration converts fractional minutes to a duration, rounding up
+// so that waiting never ends just short of the budget being available
+func minutesToDuration(minutes float64) time.Duration {
+	return time.Duration(minutes*float64(time.Minute)) + time.Millisecond
+}
+
+// estimateTokens estimates the tokens a request will use from its prompt
+// length plus its completion limit, falling back to the provider's default
+func (l *LimitedProvider) estimateTokens(req Request) int {
+	return promptTokens(req) + DefaultsOf(l.provider).Merge(req.Params).MaxTokens
+}
+
+// promptTokens estimates the tokens in a request's messages
+func promptTokens(req Request) int {
+	chars := 0
+	for _, m := range req.Messages {
+		chars += len(m.Content)
+	}
+	return textTokens(chars)
+}
+
+// textTokens estimates the tokens in text of the given length, at roughly
+// four characters per token
+func textTokens(chars int) int {
+	return (chars + 3) / 4
+}
@@ -0,0 +1,320 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeTime is a clock that advances instantly whenever something waits on it
+type fakeTime struct {
+	mu    sync.Mutex
+	now   time.Time
+	waits []time.Duration
+}
+
+func (f *fakeTime) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+func (f *fakeTime) After(d time.Duration) <-chan time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+	f.waits = append(f.waits, d)
+
+	ch := make(chan time.Time, 1)
+	ch <- f.now
+	return ch
+}
+
+// waited returns the total time spent waiting
+func (f *fakeTime) waited() time.Duration {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var total time.Duration
+	for _, d := range f.waits {
+		total += d
+	}
+	return total
+}
+
+func newTestLimited(p Provider, limits Limits) (*LimitedProvider, *fakeTime) {
+	clock := &fakeTime{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	l := Limited(p, limits)
+	l.now = clock.Now
+	l.after = clock.After
+	l.updated = clock.now
+	return l, clock
+}
+
+// approx reports whether d is within a few milliseconds of want
+func approx(d, want time.Duration) bool {
+	diff := d - want
+	return diff >= 0 && diff < 10*time.Millisecond
+}
+
+func TestLimitedRequestsPerMinute(t *testing.T) {
+	mock := &MockProvider{Response: "ok"}
+	l, clock := newTestLimited(mock, Limits{RequestsPerMinute: 2})
+
+	for i := 0; i < 3; i++ {
+		if _, err := l.Complete(context.Background(), NewRequest("Hi")); err != nil {
+			t.Fatalf("Complete() error = %v", err)
+		}
+	}
+
+	// The first two fit in the budget, the third waits for half a minute
+	if got := clock.waited(); !approx(got, 30*time.Second) {
+		t.Errorf("waited %v, want 30s", got)
+	}
+	if len(mock.Requests) != 3 {
+		t.Errorf("expected 3 requests, got %d", len(mock.Requests))
+	}
+}
+
+func TestLimitedTokensPerMinute(t *testing.T) {
+	prompt := strings.Repeat("a", 200) // about 50 tokens
+
+	t.Run("estimate", func(t *testing.T) {
+		l, clock := newTestLimited(&MockProvider{Response: "ok"}, Limits{TokensPerMinute: 100})
+
+		for i := 0; i < 3; i++ {
+			if _, err := l.Complete(context.Background(), NewRequest(prompt)); err != nil {
+				t.Fatalf("Complete() error = %v", err)
+			}
+		}
+		// Each call is charged 50 tokens and settled at 51 once the reply
+		// is counted, so the second waits for 1 token and the third for 51
+		if got := clock.waited(); !approx(got, 31200*time.Millisecond) {
+			t.Errorf("waited %v, want 31.2s", got)
+		}
+	})
+
+	t.Run("corrected by actual usage", func(t *testing.T) {
+		mock := &MockProvider{Response: "ok", Usage: Usage{TotalTokens: 80}}
+		l, clock := newTestLimited(mock, Limits{TokensPerMinute: 100})
+
+		for i := 0; i < 2; i++ {
+			if _, err := l.Complete(context.Background(), NewRequest(prompt)); err != nil {
+				t.Fatalf("Complete() error = %v", err)
+			}
+		}
+		// After the first call 20 tokens are left, so the second waits for 30 more
+		if got := clock.waited(); !approx(got, 18*time.Second) {
+			t.Errorf("waited %v, want 18s", got)
+		}
+	})
+
+	t.Run("request larger than the budget", func(t *testing.T) {
+		mock := &MockProvider{Response: "ok", Usage: Usage{TotalTokens: 20}}
+		l, clock := newTestLimited(mock, Limits{TokensPerMinute: 10})
+
+		for i := 0; i < 2; i++ {
+			if _, err := l.Complete(context.Background(), NewRequest(prompt)); err != nil {
+				t.Fatalf("Complete() error = %v", err)
+			}
+		}
+		// The first call is charged the whole budget of 10 but used 20, so
+		// the second waits for the budget to recover from -10
+		if got := clock.waited(); !approx(got, 2*time.Minute) {
+			t.Errorf("waited %v, want 2m", got)
+		}
+	})
+
+	t.Run("provider default max tokens", func(t *testing.T) {
+		mock := configuredMock{&MockProvider{Response: "ok"}, Params{MaxTokens: 100}}
+		l, clock := newTestLimited(mock, Limits{TokensPerMinute: 100})
+
+		for i := 0; i < 2; i++ {
+			if _, err := l.Complete(context.Background(), NewRequest("Hi")); err != nil {
+				t.Fatalf("Complete() error = %v", err)
+			}
+		}
+		// Each call needs the whole budget and uses 2 tokens
+		if got := clock.waited(); !approx(got, 1200*time.Millisecond) {
+			t.Errorf("waited %v, want 1.2s", got)
+		}
+	})
+
+	t.Run("failed requests are refunded", func(t *testing.T) {
+		mock := &MockProvider{Response: "ok", Usage: Usage{TotalTokens: 50}, Errors: []error{ErrServer, ErrServer}}
+		l, clock := newTestLimited(mock, Limits{TokensPerMinute: 100})
+
+		for i := 0; i < 3; i++ {
+			l.Complete(context.Background(), NewRequest(prompt))
+		}
+		if got := clock.waited(); got != 0 {
+			t.Errorf("waited %v, want no wait", got)
+		}
+	})
+
+	t.Run("streams", func(t *testing.T) {
+		mock := &MockProvider{StreamTokens: []string{"Hello", " world"}}
+		l, clock := newTestLimited(mock, Limits{TokensPerMinute: 100})
+
+		req := NewRequest(prompt)
+		req.Params.MaxTokens = 40
+		for i := 0; i < 2; i++ {
+			content, errs, err := l.Stream(context.Background(), req)
+			if err != nil {
+				t.Fatalf("Stream() error = %v", err)
+			}
+			if _, err := collect(content, errs); err != nil {
+				t.Fatalf("collect() error = %v", err)
+			}
+		}
+		// The first stream is charged 90 and settled at 53 once it ends, so
+		// the second waits for 43 more
+		if got := clock.waited(); !approx(got, 25800*time.Millisecond) {
+			t.Errorf("waited %v, want 25.8s", got)
+		}
+	})
+}
+
+// configuredMock is a mock provider with default parameters
+type configuredMock struct {
+	*MockProvider
+	defaults Params
+}
+
+func (c configuredMock) Defaults() Params {
+	return c.defaults
+}
+
+// blockingProvider counts concurrent calls and blocks until released
+type blockingProvider struct {
+	mu      sync.Mutex
+	active  int
+	maxSeen int
+	release chan struct{}
+}
+
+func (b *blockingProvider) Complete(ctx context.Context, _ Request) (Response, error) {
+	b.mu.Lock()
+	b.active++
+	b.maxSeen = max(b.maxSeen, b.active)
+	b.mu.Unlock()
+
+	defer func() {
+		b.mu.Lock()
+		b.active--
+		b.mu.Unlock()
+	}()
+
+	select {
+	case <-b.release:
+		return Response{Content: "ok"}, nil
+	case <-ctx.Done():
+		return Response{}, ctx.Err()
+	}
+}
+
+func (b *blockingProvider) Stream(ctx context.Context, req Request) (<-chan string, <-chan error, error) {
+	return nil, nil, errors.New("not implemented")
+}
+
+func TestLimitedMaxInFlight(t *testing.T) {
+	p := &blockingProvider{release: make(chan struct{})}
+	l := Limited(p, Limits{MaxInFlight: 2})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := l.Complete(context.Background(), NewRequest("Hi")); err != nil {
+				t.Errorf("Complete() error = %v", err)
+			}
+		}()
+	}
+
+	// Wait for both slots to be taken before letting requests finish
+	for {
+		p.mu.Lock()
+		active := p.active
+		p.mu.Unlock()
+		if active == 2 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	for i := 0; i < 5; i++ {
+		p.release <- struct{}{}
+	}
+	wg.Wait()
+
+	if p.maxSeen != 2 {
+		t.Errorf("max concurrent requests = %d, want 2", p.maxSeen)
+	}
+}
+
+func TestLimitedCancel(t *testing.T) {
+	p := &blockingProvider{release: make(chan struct{})}
+	l := Limited(p, Limits{MaxInFlight: 1})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		l.Complete(context.Background(), NewRequest("Hi"))
+	}()
+
+	// Wait for the first request to take the only slot
+	for {
+		p.mu.Lock()
+		active := p.active
+		p.mu.Unlock()
+		if active == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := l.Complete(ctx, NewRequest("Hi")); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("queued request error = %v, want deadline exceeded", err)
+	}
+
+	p.release <- struct{}{}
+	<-done
+
+	// The slot is free again once the first request finishes
+	go func() { p.release <- struct{}{} }()
+	if _, err := l.Complete(context.Background(), NewRequest("Hi")); err != nil {
+		t.Errorf("Complete() error = %v", err)
+	}
+}
+
+func TestLimitedStream(t *testing.T) {
+	mock := &MockProvider{StreamTokens: []string{"Hello", " world"}}
+	l := Limited(mock, Limits{MaxInFlight: 1})
+
+	content, errs, err := l.Stream(context.Background(), NewRequest("Hi"))
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+
+	// The stream holds the only slot until it has been read to the end
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := l.Complete(ctx, NewRequest("Hi")); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected to wait for the stream, got %v", err)
+	}
+
+	chunks, err := collect(content, errs)
+	if err != nil || strings.Join(chunks, "") != "Hello world" {
+		t.Errorf("collect() = %v, %v", chunks, err)
+	}
+
+	mock.Response = "ok"
+	if _, err := l.Complete(context.Background(), NewRequest("Hi")); err != nil {
+		t.Errorf("Complete() error = %v", err)
+	}
+}
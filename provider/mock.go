@@ -8,48 +8,53 @@ import (
 
 // MockProvider implements Provider interface for testing
 type MockProvider struct {
-	Response     string     // Fixed response for Complete
-	StreamTokens []string   // Tokens to stream
-	Errors       []error    // Errors to return
-	Prompts      []string   // Captured prompts for verification
-	DelayMs      int        // Optional delay to simulate network latency
-	mu           sync.Mutex // Protects concurrent access to Prompts
+	Response     string       // Fixed response for Complete
+	Responses    []Response   // Responses returned in order by Complete before falling back to Response
+	Usage        Usage        // Usage reported with every Complete response
+	StreamTokens []string     // Tokens to stream
+	Errors       []error      // Errors to return
+	Prompts      []string     // Captured prompts for verification
+	Requests     []Request    // Captured requests, including message roles
+	DelayMs      int          // Optional delay to simulate network latency
+	Supports     Capabilities // Capabilities reported to the generator
+	mu           sync.Mutex   // Protects concurrent access to the queues and captures
 }
 
-func (m *MockProvider) Complete(ctx context.Context, prompt string) (string, error) {
-	m.mu.Lock()
-	m.Prompts = append(m.Prompts, prompt)
-	m.mu.Unlock()
+func (m *MockProvider) Complete(ctx context.Context, req Request) (Response, error) {
+	m.record(req)
 
-	if len(m.Errors) > 0 {
-		err := m.Errors[0]
-		m.Errors = m.Errors[1:]
-		return "", err
+	if err := m.nextError(); err != nil {
+		return Response{}, err
 	}
 
 	if m.DelayMs > 0 {
 		select {
 		case <-time.After(time.Duration(m.DelayMs) * time.Millisecond):
 		case <-ctx.Done():
-			return "", ctx.Err()
+			return Response{}, ctx.Err()
 		}
 	}
 
-	return m.Response, nil
+	if resp, ok := m.nextResponse(); ok {
+		return resp, nil
+	}
+
+	return Response{
+		Content:      m.Response,
+		Model:        req.Params.Model,
+		FinishReason: "stop",
+		Usage:        m.Usage,
+	}, nil
 }
 
-func (m *MockProvider) Stream(ctx context.Context, prompt string) (contentChan <-chan string, errChan <-chan error, err error) {
-	m.mu.Lock()
-	m.Prompts = append(m.Prompts, prompt)
-	m.mu.Unlock()
+func (m *MockProvider) Stream(ctx context.Context, req Request) (contentChan <-chan string, errChan <-chan error, err error) {
+	m.record(req)
 
 	content := make(chan string)
 	errs := make(chan error, 1) // Buffer error channel to prevent blocking
 
 	// Return immediate error if set
-	if len(m.Errors) > 0 {
-		err := m.Errors[0]
-		m.Errors = m.Errors[1:]
+	if err := m.nextError(); err != nil {
 		close(content)
 		errs <- err
 		close(errs)
@@ -81,3 +86,45 @@ func (m *MockProvider) Stream(ctx context.Context, prompt string) (contentChan <
 
 	return content, errs, nil
 }
+
+// Name returns "mock"
+func (m *MockProvider) Name() string {
+	return "mock"
+}
+
+// Capabilities reports the configured capabilities
+func (m *MockProvider) Capabilities() Capabilities {
+	return m.Supports
+}
+
+// record captures a request for later verification
+func (m *MockProvider) record(req Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Prompts = append(m.Prompts, req.Prompt())
+	m.Requests = append(m.Requests, req)
+}
+
+// nextError pops the next queued error, if any
+func (m *MockProvider) nextError() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.Errors) == 0 {
+		return nil
+	}
+	err := m.Errors[0]
+	m.Errors = m.Errors[1:]
+	return err
+}
+
+// nextResponse pops the next queued response, if any
+func (m *MockProvider) nextResponse() (Response, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.Responses) == 0 {
+		return Response{}, false
+	}
+	resp := m.Responses[0]
+	m.Responses = m.Responses[1:]
+	return resp, true
+}
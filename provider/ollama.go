@@ -0,0 +1,352 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+const ollamaBaseURL = "http://localhost:11434"
+
+// Structured output modes for OllamaConfig.Format
+const (
+	// OllamaFormatSchema constrains responses to the request's JSON Schema
+	OllamaFormatSchema = "schema"
+	// OllamaFormatJSON only constrains responses to valid JSON, for servers
+	// that predate schema support
+	OllamaFormatJSON = "json"
+)
+
+// OllamaConfig holds configuration for the Ollama provider
+// A zero Temperature leaves the API's default in place; set
+// Params.Temperature to request a temperature of exactly 0.
+type OllamaConfig struct {
+	Model       string
+	Temperature float64
+	MaxTokens   int
+
+	// BaseURL defaults to http://localhost:11434
+	BaseURL string
+	// Generate sends requests to /api/generate instead of /api/chat. Messages
+	// are flattened into a single prompt, with system messages sent separately.
+	Generate bool
+	// Format selects how structured outputs are requested when the request
+	// carries a schema: OllamaFormatSchema (default) or OllamaFormatJSON
+	Format string
+	// HTTPClient defaults to http.DefaultClient
+	HTTPClient *http.Client
+}
+
+// Ollama implements the Provider interface using a local Ollama server
+type Ollama struct {
+	client *http.Client
+	config OllamaConfig
+}
+
+// DefaultOllama creates a new Ollama provider with default configuration.
+// The server address is read from OLLAMA_HOST when set.
+func DefaultOllama() (*Ollama, error) {
+	baseURL := os.Getenv("OLLAMA_HOST")
+	if baseURL != "" && !strings.Contains(baseURL, "://") {
+		baseURL = "http://" + baseURL
+	}
+
+	return NewOllama(OllamaConfig{
+		Model:       "llama3.2",
+		Temperature: 0.7,
+		MaxTokens:   2000,
+		BaseURL:     baseURL,
+	})
+}
+
+// NewOllama creates a new Ollama provider with the given configuration
+func NewOllama(config OllamaConfig) (*Ollama, error) {
+	if config.Model == "" {
+		return nil, fmt.Errorf("model is required")
+	}
+	if config.BaseURL == "" {
+		config.BaseURL = ollamaBaseURL
+	}
+	switch config.Format {
+	case "":
+		config.Format = OllamaFormatSchema
+	case OllamaFormatSchema, OllamaFormatJSON:
+	default:
+		return nil, fmt.Errorf("unknown format %q", config.Format)
+	}
+
+	client := config.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &Ollama{
+		client: client,
+		config: config,
+	}, nil
+}
+
+// Name returns "ollama"
+func (o *Ollama) Name() string {
+	return "ollama"
+}
+
+// Defaults returns the configured model, temperature and token limit
+func (o *Ollama) Defaults() Params {
+	return Params{
+		Model:       o.config.Model,
+		Temperature: configFloat64(o.config.Temperature),
+		MaxTokens:   o.config.MaxTokens,
+	}
+}
+
+// Capabilities reports native structured output according to the configured format
+func (o *Ollama) Capabilities() Capabilities {
+	return Capabilities{
+		JSONSchema: o.config.Format == OllamaFormatSchema,
+		JSONMode:   true,
+	}
+}
+
+// ollamaMessage is a message in the chat API format
+type ollamaMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// ollamaOptions are the model options supported by both endpoints
+type ollamaOptions struct {
+	Temperature      *float64 `json:"temperature,omitempty"`
+	TopP             *float64 `json:"top_p,omitempty"`
+	NumPredict       int      `json:"num_predict,omitempty"`
+	Stop             []string `json:"stop,omitempty"`
+	Seed             *int     `json:"seed,omitempty"`
+	PresencePenalty  *float64 `json:"presence_penalty,omitempty"`
+	FrequencyPenalty *float64 `json:"frequency_penalty,omitempty"`
+}
+
+// ollamaRequest is the body of a chat or generate request; Messages is used
+// by /api/chat, Prompt and System by /api/generate
+type ollamaRequest struct {
+	Model    string          `json:"model"`
+	Messages []ollamaMessage `json:"messages,omitempty"`
+	Prompt   string          `json:"prompt,omitempty"`
+	System   string          `json:"system,omitempty"`
+	Format   json.RawMessage `json:"format,omitempty"`
+	Options  ollamaOptions   `json:"options"`
+	Stream   bool            `json:"stream"`
+}
+
+// ollamaResponse is a full response, or a single line of a streamed one.
+// The chat API returns content in Message, the generate API in Response.
+type ollamaResponse struct {
+	Model           string        `json:"model"`
+	Message         ollamaMessage `json:"message"`
+	Response        string        `json:"response"`
+	Done            bool          `json:"done"`
+	DoneReason      string        `json:"done_reason"`
+	PromptEvalCount int           `json:"prompt_eval_count"`
+	EvalCount       int           `json:"eval_count"`
+	Error           string        `json:"error"`
+}
+
+// content returns the generated text regardless of endpoint
+func (r ollamaResponse) content() string {
+	if r.Message.Content != "" {
+		return r.Message.Content
+	}
+	return r.Response
+}
+
+// Complete generates a completion for the given request using Ollama
+func (o *Ollama) Complete(ctx context.Context, req Request) (Response, error) {
+	httpResp, err := o.do(ctx, o.ollamaRequest(req, false))
+	if err != nil {
+		return Response{}, err
+	}
+	defer httpResp.Body.Close()
+
+	var resp ollamaResponse
+	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+		return Response{}, fmt.Errorf("ollama completion failed: invalid response: %w", err)
+	}
+	if resp.Error != "" {
+		return Response{}, ollamaAPIError(0, resp.Error)
+	}
+
+	return Response{
+		Content:      resp.content(),
+		Model:        resp.Model,
+		FinishReason: resp.DoneReason,
+		Usage: Usage{
+			PromptTokens:     resp.PromptEvalCount,
+			CompletionTokens: resp.EvalCount,
+			TotalTokens:      resp.PromptEvalCount + resp.EvalCount,
+		},
+	}, nil
+}
+
+// Stream generates a completion and streams the response using Ollama.
+// The server sends one JSON object per line until one is marked done.
+func (o *Ollama) Stream(ctx context.Context, req Request) (contentChan <-chan string, errChan <-chan error, err error) {
+	httpResp, err := o.do(ctx, o.ollamaRequest(req, true))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content := make(chan string)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer httpResp.Body.Close()
+		defer close(content)
+		defer close(errs)
+
+		decoder := json.NewDecoder(httpResp.Body)
+		for {
+			var chunk ollamaResponse
+			if err := decoder.Decode(&chunk); err != nil {
+				if errors.Is(err, io.EOF) {
+					return
+				}
+				if ctx.Err() != nil {
+					errs <- ctx.Err()
+					return
+				}
+				errs <- fmt.Errorf("stream receive failed: %w", err)
+				return
+			}
+			if chunk.Error != "" {
+				errs <- ollamaAPIError(0, chunk.Error)
+				return
+			}
+
+			if text := chunk.content(); text != "" {
+				select {
+				case content <- text:
+				case <-ctx.Done():
+					errs <- ctx.Err()
+					return
+				}
+			}
+			if chunk.Done {
+				return
+			}
+		}
+	}()
+
+	return content, errs, nil
+}
+
+// ollamaRequest converts a Request into a chat or generate request
+func (o *Ollama) ollamaRequest(req Request, stream bool) ollamaRequest {
+	params := o.Defaults().Merge(req.Params)
+
+	body := ollamaRequest{
+		Model: params.Model,
+		Options: ollamaOptions{
+			Temperature:      params.Temperature,
+			TopP:             params.TopP,
+			NumPredict:       params.MaxTokens,
+			Stop:             params.Stop,
+			Seed:             params.Seed,
+			PresencePenalty:  params.PresencePenalty,
+			FrequencyPenalty: params.FrequencyPenalty,
+		},
+		Stream: stream,
+	}
+
+	if len(req.Schema) > 0 {
+		if o.config.Format == OllamaFormatJSON {
+			body.Format = json.RawMessage(`"json"`)
+		} else {
+			body.Format = req.Schema
+		}
+	}
+
+	if !o.config.Generate {
+		body.Messages = make([]ollamaMessage, len(req.Messages))
+		for i, m := range req.Messages {
+			body.Messages[i] = ollamaMessage{Role: string(m.Role), Content: m.Content}
+		}
+		return body
+	}
+
+	// The generate API takes a single prompt, with the system prompt separate
+	var system, prompt []string
+	for _, m := range req.Messages {
+		if m.Role == RoleSystem {
+			system = append(system, m.Content)
+		} else {
+			prompt = append(prompt, m.Content)
+		}
+	}
+	body.System = strings.Join(system, "\n\n")
+	body.Prompt = strings.Join(prompt, "\n\n")
+	return body
+}
+
+// do sends a request to the configured endpoint and maps error responses
+func (o *Ollama) do(ctx context.Context, body ollamaRequest) (*http.Response, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	endpoint := "/api/chat"
+	if o.config.Generate {
+		endpoint = "/api/generate"
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(o.config.BaseURL, "/")+endpoint, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	httpResp, err := o.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("ollama request failed: %w", err)
+	}
+
+	if httpResp.StatusCode != http.StatusOK {
+		defer httpResp.Body.Close()
+
+		var body struct {
+			Error string `json:"error"`
+		}
+		if err := json.NewDecoder(httpResp.Body).Decode(&body); err != nil || body.Error == "" {
+			body.Error = http.StatusText(httpResp.StatusCode)
+		}
+		return nil, withRetryAfter(ollamaAPIError(httpResp.StatusCode, body.Error), httpResp.Header)
+	}
+
+	return httpResp, nil
+}
+
+// ollamaAPIError maps an API error to the provider's error types
+func ollamaAPIError(status int, message string) error {
+	err := fmt.Errorf("ollama api error: %s", message)
+	if status != 0 {
+		err = fmt.Errorf("ollama api error (status %d): %s", status, message)
+	}
+
+	msg := strings.ToLower(message)
+	switch {
+	// Ollama answers 503 when its request queue is full
+	case status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable,
+		strings.Contains(msg, "server busy"):
+		return fmt.Errorf("%w: %v", ErrRateLimit, err)
+	case strings.Contains(msg, "context length") || strings.Contains(msg, "context window"):
+		return fmt.Errorf("%w: %v", ErrContextLength, err)
+	case status >= 500:
+		return fmt.Errorf("%w: %v", ErrServer, err)
+	}
+	return err
+}
@@ -0,0 +1,296 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+// newOllamaTestServer starts a server that checks the endpoint and hands the
+// decoded body to handle
+func newOllamaTestServer(t *testing.T, config OllamaConfig, handle func(w http.ResponseWriter, body ollamaRequest)) *Ollama {
+	t.Helper()
+
+	wantPath := "/api/chat"
+	if config.Generate {
+		wantPath = "/api/generate"
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != wantPath {
+			t.Errorf("path = %s, want %s", r.URL.Path, wantPath)
+		}
+
+		var body ollamaRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("invalid request body: %v", err)
+		}
+		handle(w, body)
+	}))
+	t.Cleanup(server.Close)
+
+	config.BaseURL = server.URL
+	if config.Model == "" {
+		config.Model = "llama3.2"
+	}
+	provider, err := NewOllama(config)
+	if err != nil {
+		t.Fatalf("NewOllama() error = %v", err)
+	}
+	return provider
+}
+
+// replayNDJSON writes a recorded newline-delimited JSON stream from testdata
+func replayNDJSON(t *testing.T, w http.ResponseWriter, name string) {
+	t.Helper()
+
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatalf("failed to read recording: %v", err)
+	}
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Write(data)
+}
+
+// collect drains a stream, returning its chunks and final error
+func collect(content <-chan string, errs <-chan error) ([]string, error) {
+	var chunks []string
+	for chunk := range content {
+		chunks = append(chunks, chunk)
+	}
+	return chunks, <-errs
+}
+
+func TestOllamaComplete(t *testing.T) {
+	schema := json.RawMessage(`{"type":"object","properties":{"response":{"type":"string"}}}`)
+
+	provider := newOllamaTestServer(t, OllamaConfig{Temperature: 0.7, MaxTokens: 100}, func(w http.ResponseWriter, body ollamaRequest) {
+		if body.Model != "qwen2.5" {
+			t.Errorf("model = %q, want qwen2.5", body.Model)
+		}
+		if len(body.Messages) != 2 || body.Messages[0].Role != "system" || body.Messages[1].Role != "user" {
+			t.Errorf("unexpected messages: %+v", body.Messages)
+		}
+		if body.Stream {
+			t.Error("stream should be false")
+		}
+		if string(body.Format) != string(schema) {
+			t.Errorf("format = %s, want the request schema", body.Format)
+		}
+		if body.Options.Temperature == nil || *body.Options.Temperature != 0 {
+			t.Errorf("temperature = %v, want 0", body.Options.Temperature)
+		}
+		if body.Options.NumPredict != 100 || body.Options.Seed == nil || *body.Options.Seed != 7 {
+			t.Errorf("unexpected options: %+v", body.Options)
+		}
+
+		w.Write([]byte(`{
+			"model": "qwen2.5",
+			"message": {"role": "assistant", "content": "{\"response\": \"Hello\"}"},
+			"done": true,
+			"done_reason": "stop",
+			"prompt_eval_count": 20,
+			"eval_count": 6
+		}`))
+	})
+
+	resp, err := provider.Complete(context.Background(), Request{
+		Messages: []Message{
+			{Role: RoleSystem, Content: "Be brief."},
+			{Role: RoleUser, Content: "Hi"},
+		},
+		Params: Params{Model: "qwen2.5", Temperature: Float64(0), Seed: Int(7)},
+		Schema: schema,
+	})
+	if err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+
+	if resp.Content != `{"response": "Hello"}` {
+		t.Errorf("content = %q", resp.Content)
+	}
+	if resp.Model != "qwen2.5" || resp.FinishReason != "stop" {
+		t.Errorf("unexpected model or finish reason: %q %q", resp.Model, resp.FinishReason)
+	}
+	if resp.Usage != (Usage{PromptTokens: 20, CompletionTokens: 6, TotalTokens: 26}) {
+		t.Errorf("unexpected usage: %+v", resp.Usage)
+	}
+}
+
+func TestOllamaFormat(t *testing.T) {
+	schema := json.RawMessage(`{"type":"object"}`)
+
+	tests := []struct {
+		name   string
+		format string
+		schema json.RawMessage
+		want   string
+	}{
+		{name: "schema", schema: schema, want: `{"type":"object"}`},
+		{name: "json mode", format: OllamaFormatJSON, schema: schema, want: `"json"`},
+		{name: "no schema", format: OllamaFormatJSON, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := newOllamaTestServer(t, OllamaConfig{Format: tt.format}, func(w http.ResponseWriter, body ollamaRequest) {
+				if string(body.Format) != tt.want {
+					t.Errorf("format = %s, want %s", body.Format, tt.want)
+				}
+				w.Write([]byte(`{"model":"llama3.2","message":{"role":"assistant","content":"{}"},"done":true}`))
+			})
+
+			if caps := provider.Capabilities(); caps.JSONSchema != (tt.format != OllamaFormatJSON) || !caps.JSONMode {
+				t.Errorf("unexpected capabilities: %+v", caps)
+			}
+
+			req := NewRequest("Hi")
+			req.Schema = tt.schema
+			if _, err := provider.Complete(context.Background(), req); err != nil {
+				t.Fatalf("Complete() error = %v", err)
+			}
+		})
+	}
+
+	if _, err := NewOllama(OllamaConfig{Model: "llama3.2", Format: "yaml"}); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestOllamaGenerate(t *testing.T) {
+	provider := newOllamaTestServer(t, OllamaConfig{Generate: true}, func(w http.ResponseWriter, body ollamaRequest) {
+		if body.System != "Be brief." {
+			t.Errorf("system = %q, want %q", body.System, "Be brief.")
+		}
+		if body.Prompt != "Hi\n\nHello" {
+			t.Errorf("prompt = %q", body.Prompt)
+		}
+		if len(body.Messages) != 0 {
+			t.Errorf("messages should not be sent, got %+v", body.Messages)
+		}
+		replayNDJSON(t, w, "ollama_generate_stream.ndjson")
+	})
+
+	content, errs, err := provider.Stream(context.Background(), Request{
+		Messages: []Message{
+			{Role: RoleSystem, Content: "Be brief."},
+			{Role: RoleUser, Content: "Hi"},
+			{Role: RoleAssistant, Content: "Hello"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+
+	chunks, err := collect(content, errs)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if got := strings.Join(chunks, ""); got != "Hello world" {
+		t.Errorf("streamed %q", got)
+	}
+}
+
+func TestOllamaStream(t *testing.T) {
+	t.Run("recorded stream", func(t *testing.T) {
+		provider := newOllamaTestServer(t, OllamaConfig{}, func(w http.ResponseWriter, body ollamaRequest) {
+			if !body.Stream {
+				t.Error("stream should be set")
+			}
+			replayNDJSON(t, w, "ollama_chat_stream.ndjson")
+		})
+
+		content, errs, err := provider.Stream(context.Background(), NewRequest("Hi"))
+		if err != nil {
+			t.Fatalf("Stream() error = %v", err)
+		}
+
+		chunks, err := collect(content, errs)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if got := strings.Join(chunks, ""); got != `{"response": "Hello world"}` {
+			t.Errorf("streamed %q", got)
+		}
+		if len(chunks) != 3 {
+			t.Errorf("expected 3 chunks, got %d", len(chunks))
+		}
+	})
+
+	t.Run("error mid-stream", func(t *testing.T) {
+		provider := newOllamaTestServer(t, OllamaConfig{}, func(w http.ResponseWriter, _ ollamaRequest) {
+			replayNDJSON(t, w, "ollama_stream_error.ndjson")
+		})
+
+		content, errs, err := provider.Stream(context.Background(), NewRequest("Hi"))
+		if err != nil {
+			t.Fatalf("Stream() error = %v", err)
+		}
+
+		chunks, err := collect(content, errs)
+		if err == nil || !strings.Contains(err.Error(), "unexpected EOF") {
+			t.Errorf("expected model error, got %v", err)
+		}
+		if len(chunks) != 1 || chunks[0] != "Hel" {
+			t.Errorf("unexpected chunks: %v", chunks)
+		}
+	})
+}
+
+func TestOllamaErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   error
+	}{
+		{
+			name:   "server busy",
+			status: http.StatusServiceUnavailable,
+			body:   `{"error":"server busy, please try again.  maximum pending requests exceeded"}`,
+			want:   ErrRateLimit,
+		},
+		{
+			name:   "context length",
+			status: http.StatusBadRequest,
+			body:   `{"error":"input length exceeds maximum context length"}`,
+			want:   ErrContextLength,
+		},
+		{
+			name:   "server error",
+			status: http.StatusInternalServerError,
+			body:   `{"error":"llama runner process has terminated: signal: killed"}`,
+			want:   ErrServer,
+		},
+		{
+			name:   "model not found",
+			status: http.StatusNotFound,
+			body:   `{"error":"model \"llama3.2\" not found, try pulling it first"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := newOllamaTestServer(t, OllamaConfig{}, func(w http.ResponseWriter, _ ollamaRequest) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			})
+
+			_, err := provider.Complete(context.Background(), NewRequest("Hi"))
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("error = %v, want %v", err, tt.want)
+			}
+			if tt.want == nil && (errors.Is(err, ErrRateLimit) || errors.Is(err, ErrContextLength) || errors.Is(err, ErrServer)) {
+				t.Errorf("error should not be classified, got %v", err)
+			}
+		})
+	}
+}
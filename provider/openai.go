@@ -5,18 +5,55 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
+	"net/http"
 	"os"
 	"strings"
 
 	"github.com/sashabaranov/go-openai"
 )
 
+// Structured output modes for OpenAIConfig.ResponseFormat
+const (
+	// OpenAIFormatJSONSchema sends the request's JSON Schema as a json_schema
+	// response format, for models that support structured outputs
+	OpenAIFormatJSONSchema = "json_schema"
+	// OpenAIFormatJSON requests JSON mode, which only guarantees valid JSON
+	OpenAIFormatJSON = "json_object"
+)
+
 // OpenAIConfig holds configuration for the OpenAI provider
+// A zero Temperature leaves the API's default in place; set
+// Params.Temperature to request a temperature of exactly 0.
 type OpenAIConfig struct {
 	APIKey      string
 	Model       string
 	Temperature float64
 	MaxTokens   int
+
+	// BaseURL points the provider at any OpenAI-compatible endpoint, such as
+	// vLLM, LiteLLM or a proxy. Defaults to https://api.openai.com/v1. For
+	// Azure it is the resource endpoint, e.g. https://example.openai.azure.com
+	BaseURL string
+	// Organization is sent as the OpenAI-Organization header
+	Organization string
+	// Azure switches to Azure OpenAI, where models are addressed by
+	// deployment name and the API key is sent in the api-key header
+	Azure bool
+	// APIVersion is the Azure OpenAI API version
+	APIVersion string
+	// Deployments maps model names to Azure deployment names. Models without
+	// an entry use the model name with dots and colons removed.
+	Deployments map[string]string
+	// Headers are added to every request
+	Headers http.Header
+	// HTTPClient defaults to http.DefaultClient
+	HTTPClient *http.Client
+	// ResponseFormat enables native structured output when the request
+	// carries a schema: OpenAIFormatJSONSchema or OpenAIFormatJSON. Leave it
+	// empty for models and servers without support; the prompt alone then
+	// describes the expected format.
+	ResponseFormat string
 }
 
 // OpenAI implements the Provider interface using OpenAI's API
@@ -40,75 +77,168 @@ func DefaultOpenAI() (*OpenAI, error) {
 	})
 }
 
-// NewOpenAI creates a new OpenAI provider with the given configuration
+// NewOpenAI creates a new OpenAI provider with the given configuration.
+// An API key is required unless BaseURL points somewhere other than OpenAI.
 func NewOpenAI(config OpenAIConfig) (*OpenAI, error) {
-	if config.APIKey == "" {
+	if config.APIKey == "" && (config.BaseURL == "" || config.Azure) {
 		return nil, fmt.Errorf("API key is required")
 	}
 
+	switch config.ResponseFormat {
+	case "", OpenAIFormatJSONSchema, OpenAIFormatJSON:
+	default:
+		return nil, fmt.Errorf("unknown response format %q", config.ResponseFormat)
+	}
+
+	var clientConfig openai.ClientConfig
+	if config.Azure {
+		if config.BaseURL == "" {
+			return nil, fmt.Errorf("base URL is required for Azure")
+		}
+		clientConfig = openai.DefaultAzureConfig(config.APIKey, config.BaseURL)
+		if config.APIVersion != "" {
+			clientConfig.APIVersion = config.APIVersion
+		}
+		defaultMapper := clientConfig.AzureModelMapperFunc
+		clientConfig.AzureModelMapperFunc = func(model string) string {
+			if deployment, ok := config.Deployments[model]; ok {
+				return deployment
+			}
+			return defaultMapper(model)
+		}
+	} else {
+		clientConfig = openai.DefaultConfig(config.APIKey)
+		if config.BaseURL != "" {
+			clientConfig.BaseURL = strings.TrimRight(config.BaseURL, "/")
+		}
+	}
+	clientConfig.OrgID = config.Organization
+
+	var client openai.HTTPDoer = http.DefaultClient
+	if config.HTTPClient != nil {
+		client = config.HTTPClient
+	}
+	if len(config.Headers) > 0 {
+		client = &headerDoer{client: client, headers: config.Headers}
+	}
+	clientConfig.HTTPClient = &retryAfterDoer{client: client}
+
 	return &OpenAI{
-		client: openai.NewClient(config.APIKey),
+		client: openai.NewClientWithConfig(clientConfig),
 		config: config,
 	}, nil
 }
 
-// Complete generates a completion for the given prompt using OpenAI's API
-func (o *OpenAI) Complete(ctx context.Context, prompt string) (string, error) {
-	resp, err := o.client.CreateChatCompletion(
-		ctx,
-		openai.ChatCompletionRequest{
-			Model: o.config.Model,
-			Messages: []openai.ChatCompletionMessage{
-				{
-					Role:    openai.ChatMessageRoleUser,
-					Content: prompt,
-				},
-			},
-			Temperature: float32(o.config.Temperature),
-			MaxTokens:   o.config.MaxTokens,
-		},
-	)
+// headerDoer adds extra headers to every request before sending it
+type headerDoer struct {
+	client  openai.HTTPDoer
+	headers http.Header
+}
 
-	if err != nil {
-		var apiErr *openai.APIError
-		if errors.As(err, &apiErr) {
-			switch apiErr.HTTPStatusCode {
-			case 429:
-				return "", fmt.Errorf("%w: %v", ErrRateLimit, err)
-			case 400:
-				if strings.Contains(apiErr.Message, "maximum context length") {
-					return "", fmt.Errorf("%w: %v", ErrContextLength, err)
-				}
-			}
+func (d *headerDoer) Do(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for key, values := range d.headers {
+		req.Header.Del(key)
+		for _, value := range values {
+			req.Header.Add(key, value)
 		}
-		return "", fmt.Errorf("openai completion failed: %w", err)
 	}
+	return d.client.Do(req)
+}
+
+// retryHintKey is the context key for the *http.Header that receives the
+// headers of a rate limited or failed response
+type retryHintKey struct{}
 
-	return resp.Choices[0].Message.Content, nil
+// withRetryHint returns a context whose requests record the headers of a
+// rate limited or failed response into the returned header
+func withRetryHint(ctx context.Context) (context.Context, *http.Header) {
+	header := new(http.Header)
+	return context.WithValue(ctx, retryHintKey{}, header), header
 }
 
-// Stream generates a completion and streams the response using OpenAI's API
-func (o *OpenAI) Stream(ctx context.Context, prompt string) (contentChan <-chan string, errChan <-chan error, err error) {
-	stream, err := o.client.CreateChatCompletionStream(
-		ctx,
-		openai.ChatCompletionRequest{
-			Model: o.config.Model,
-			Messages: []openai.ChatCompletionMessage{
-				{
-					Role:    openai.ChatMessageRoleUser,
-					Content: prompt,
-				},
-			},
-			Temperature: float32(o.config.Temperature),
-			MaxTokens:   o.config.MaxTokens,
+// retryAfterDoer records the headers of 429 and 5xx responses so that their
+// Retry-After hint survives go-openai's error handling
+type retryAfterDoer struct {
+	client openai.HTTPDoer
+}
+
+func (d *retryAfterDoer) Do(req *http.Request) (*http.Response, error) {
+	resp, err := d.client.Do(req)
+	if err == nil && (resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500) {
+		if header, ok := req.Context().Value(retryHintKey{}).(*http.Header); ok {
+			*header = resp.Header.Clone()
+		}
+	}
+	return resp, err
+}
+
+// Name returns "openai"
+func (o *OpenAI) Name() string {
+	return "openai"
+}
+
+// Defaults returns the configured model, temperature and token limit
+func (o *OpenAI) Defaults() Params {
+	return Params{
+		Model:       o.config.Model,
+		Temperature: configFloat64(o.config.Temperature),
+		MaxTokens:   o.config.MaxTokens,
+	}
+}
+
+// Capabilities reports native structured output according to the configured response format
+func (o *OpenAI) Capabilities() Capabilities {
+	return Capabilities{
+		JSONSchema: o.config.ResponseFormat == OpenAIFormatJSONSchema,
+		JSONMode:   o.config.ResponseFormat != "",
+	}
+}
+
+// Complete generates a completion for the given request using OpenAI's API
+func (o *OpenAI) Complete(ctx context.Context, req Request) (Response, error) {
+	ctx, header := withRetryHint(ctx)
+	resp, err := o.client.CreateChatCompletion(ctx, o.chatRequest(req))
+
+	if err != nil {
+		return Response{}, withRetryAfter(openAIError("openai completion failed", err), *header)
+	}
+	if len(resp.Choices) == 0 {
+		return Response{}, fmt.Errorf("openai completion returned no choices")
+	}
+
+	var toolCalls []ToolCall
+	for _, call := range resp.Choices[0].Message.ToolCalls {
+		toolCalls = append(toolCalls, ToolCall{
+			ID:        call.ID,
+			Name:      call.Function.Name,
+			Arguments: call.Function.Arguments,
+		})
+	}
+
+	return Response{
+		Content:      resp.Choices[0].Message.Content,
+		Model:        resp.Model,
+		FinishReason: string(resp.Choices[0].FinishReason),
+		Usage: Usage{
+			PromptTokens:     resp.Usage.PromptTokens,
+			CompletionTokens: resp.Usage.CompletionTokens,
+			TotalTokens:      resp.Usage.TotalTokens,
 		},
-	)
+		ToolCalls: toolCalls,
+	}, nil
+}
+
+// Stream generates a completion and streams the response using OpenAI's API
+func (o *OpenAI) Stream(ctx context.Context, req Request) (contentChan <-chan string, errChan <-chan error, err error) {
+	ctx, header := withRetryHint(ctx)
+	stream, err := o.client.CreateChatCompletionStream(ctx, o.chatRequest(req))
 	if err != nil {
-		return nil, nil, fmt.Errorf("openai stream failed: %w", err)
+		return nil, nil, withRetryAfter(openAIError("openai stream failed", err), *header)
 	}
 
 	content := make(chan string)
-	errs := make(chan error)
+	errs := make(chan error, 1)
 
 	go func() {
 		defer stream.Close()
@@ -126,10 +256,131 @@ func (o *OpenAI) Stream(ctx context.Context, prompt string) (contentChan <-chan
 			}
 
 			if len(response.Choices) > 0 {
-				content <- response.Choices[0].Delta.Content
+				select {
+				case content <- response.Choices[0].Delta.Content:
+				case <-ctx.Done():
+					errs <- ctx.Err()
+					return
+				}
 			}
 		}
 	}()
 
 	return content, errs, nil
 }
+
+// openAIError maps an API error to the provider's error types
+func openAIError(msg string, err error) error {
+	var apiErr *openai.APIError
+	var reqErr *openai.RequestError
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.HTTPStatusCode == 429:
+			return fmt.Errorf("%w: %v", ErrRateLimit, err)
+		case apiErr.HTTPStatusCode == 400:
+			if strings.Contains(apiErr.Message, "maximum context length") {
+				return fmt.Errorf("%w: %v", ErrContextLength, err)
+			}
+		case apiErr.HTTPStatusCode >= 500:
+			return fmt.Errorf("%w: %v", ErrServer, err)
+		}
+	} else if errors.As(err, &reqErr) && reqErr.HTTPStatusCode >= 500 {
+		return fmt.Errorf("%w: %v", ErrServer, err)
+	}
+	return fmt.Errorf("%s: %w", msg, err)
+}
+
+// chatRequest converts a Request into an OpenAI chat completion request
+func (o *OpenAI) chatRequest(req Request) openai.ChatCompletionRequest {
+	messages := make([]openai.ChatCompletionMessage, len(req.Messages))
+	for i, m := range req.Messages {
+		messages[i] = openai.ChatCompletionMessage{
+			Role:       openAIRole(m.Role),
+			Content:    m.Content,
+			ToolCallID: m.ToolCallID,
+		}
+		for _, call := range m.ToolCalls {
+			messages[i].ToolCalls = append(messages[i].ToolCalls, openai.ToolCall{
+				ID:   call.ID,
+				Type: openai.ToolTypeFunction,
+				Function: openai.FunctionCall{
+					Name:      call.Name,
+					Arguments: call.Arguments,
+				},
+			})
+		}
+	}
+
+	// Per-request parameters override the provider configuration
+	params := o.Defaults().Merge(req.Params)
+
+	chatReq := openai.ChatCompletionRequest{
+		Model:     params.Model,
+		Messages:  messages,
+		MaxTokens: params.MaxTokens,
+		Stop:      params.Stop,
+		Seed:      params.Seed,
+	}
+	if params.Temperature != nil {
+		chatReq.Temperature = float32(*params.Temperature)
+		if chatReq.Temperature == 0 {
+			// go-openai omits zero values, which the API treats as the default
+			// temperature of 1; the smallest non-zero value keeps it deterministic
+			chatReq.Temperature = math.SmallestNonzeroFloat32
+		}
+	}
+	if params.TopP != nil {
+		chatReq.TopP = float32(*params.TopP)
+	}
+	if params.PresencePenalty != nil {
+		chatReq.PresencePenalty = float32(*params.PresencePenalty)
+	}
+	if params.FrequencyPenalty != nil {
+		chatReq.FrequencyPenalty = float32(*params.FrequencyPenalty)
+	}
+
+	for _, tool := range req.Tools {
+		chatReq.Tools = append(chatReq.Tools, openai.Tool{
+			Type: openai.ToolTypeFunction,
+			Function: &openai.FunctionDefinition{
+				Name:        tool.Name,
+				Description: tool.Description,
+				Parameters:  tool.Parameters,
+			},
+		})
+	}
+
+	if len(req.Schema) > 0 {
+		switch o.config.ResponseFormat {
+		case OpenAIFormatJSONSchema:
+			// Strict mode would reject schemas with optional properties
+			chatReq.ResponseFormat = &openai.ChatCompletionResponseFormat{
+				Type: openai.ChatCompletionResponseFormatTypeJSONSchema,
+				JSONSchema: &openai.ChatCompletionResponseFormatJSONSchema{
+					Name:   "response",
+					Schema: req.Schema,
+				},
+			}
+		case OpenAIFormatJSON:
+			chatReq.ResponseFormat = &openai.ChatCompletionResponseFormat{
+				Type: openai.ChatCompletionResponseFormatTypeJSONObject,
+			}
+		}
+	}
+
+	return chatReq
+}
+
+// openAIRole maps a message role to its OpenAI equivalent
+func openAIRole(role Role) string {
+	switch role {
+	case RoleSystem:
+		return openai.ChatMessageRoleSystem
+	case RoleAssistant:
+		return openai.ChatMessageRoleAssistant
+	case RoleTool:
+		return openai.ChatMessageRoleTool
+	default:
+		return openai.ChatMessageRoleUser
+	}
+}
@@ -5,7 +5,10 @@ package provider
 
 import (
 	"context"
-	"errors"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"strings"
 	"testing"
@@ -25,16 +28,6 @@ func TestOpenAICompletion(t *testing.T) {
 		t.Skip("Skipping OpenAI integration test: OPENAI_API_KEY not set")
 	}
 
-	provider, err := NewOpenAI(OpenAIConfig{
-		APIKey:      apiKey,
-		Model:       "gpt-3.5-turbo",
-		Temperature: 0.7,
-		MaxTokens:   100,
-	})
-	if err != nil {
-		t.Fatalf("failed to create provider: %v", err)
-	}
-
 	tests := []struct {
 		name        string
 		prompt      string
@@ -112,7 +105,7 @@ func TestOpenAICompletion(t *testing.T) {
 			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 			defer cancel()
 
-			resp, err := provider.Complete(ctx, tt.prompt)
+			resp, err := provider.Complete(ctx, NewRequest(tt.prompt))
 			if tt.wantErr {
 				if err == nil {
 					t.Error("expected error, got nil")
@@ -125,7 +118,7 @@ func TestOpenAICompletion(t *testing.T) {
 				t.Errorf("unexpected error: %v", err)
 				return
 			}
-			if resp == "" {
+			if resp.Content == "" {
 				t.Error("expected non-empty response")
 			}
 		})
@@ -138,12 +131,11 @@ func TestOpenAIStreaming(t *testing.T) {
 		t.Skip("Skipping OpenAI integration test: OPENAI_API_KEY not set")
 	}
 
-	provider := NewOpenAI(apiKey)
 	tests := []struct {
 		name        string
 		prompt      string
 		model       string
-		temperature float32
+		temperature float64
 		maxTokens   int
 		wantErr     bool
 		errContains string
@@ -178,35 +170,20 @@ func TestOpenAIStreaming(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			provider, err := NewOpenAI(OpenAIConfig{
+				APIKey:      apiKey,
+				Model:       tt.model,
+				Temperature: tt.temperature,
+				MaxTokens:   tt.maxTokens,
+			})
+			if err != nil {
+				t.Fatalf("failed to create provider: %v", err)
+			}
+
 			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 			defer cancel()
 
-			ch := make(chan string)
-			errCh := make(chan error)
-
-			go provider.Stream(ctx, tt.prompt, tt.model, tt.temperature, tt.maxTokens, ch, errCh)
-
-			var tokens []string
-			var streamErr error
-
-			// Use a select to handle both success and error cases
-			for {
-				select {
-				case token, ok := <-ch:
-					if !ok {
-						// Channel closed, exit loop
-						goto done
-					}
-					tokens = append(tokens, token)
-				case err := <-errCh:
-					streamErr = err
-					goto done
-				case <-ctx.Done():
-					t.Fatal("test timed out")
-				}
-			}
-
-		done:
+			tokens, streamErr := collectOpenAIStream(provider.Stream(ctx, NewRequest(tt.prompt)))
 			if tt.wantErr {
 				if streamErr == nil {
 					t.Error("expected error, got nil")
@@ -226,6 +203,19 @@ func TestOpenAIStreaming(t *testing.T) {
 	}
 }
 
+// collectOpenAIStream reads a stream to the end, returning its chunks and
+// the first error from either the call or the stream
+func collectOpenAIStream(content <-chan string, errs <-chan error, err error) ([]string, error) {
+	if err != nil {
+		return nil, err
+	}
+	var tokens []string
+	for token := range content {
+		tokens = append(tokens, token)
+	}
+	return tokens, <-errs
+}
+
 func TestDefaultOpenAI(t *testing.T) {
 	originalAPIKey := os.Getenv("OPENAI_API_KEY")
 	defer os.Setenv("OPENAI_API_KEY", originalAPIKey)
@@ -313,17 +303,28 @@ func TestNewOpenAI(t *testing.T) {
 	}
 }
 
-type mockOpenAIClient struct {
-	createChatCompletionFunc       func(context.Context, openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error)
-	createChatCompletionStreamFunc func(context.Context, openai.ChatCompletionRequest) (*openai.ChatCompletionStream, error)
-}
+// newTestOpenAI returns a provider that talks to a local server running handler
+func newTestOpenAI(t *testing.T, config OpenAIConfig, handler http.HandlerFunc) *OpenAI {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
 
-func (m *mockOpenAIClient) CreateChatCompletion(ctx context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
-	return m.createChatCompletionFunc(ctx, req)
+	clientConfig := openai.DefaultConfig(config.APIKey)
+	clientConfig.BaseURL = server.URL + "/v1"
+	return &OpenAI{
+		client: openai.NewClientWithConfig(clientConfig),
+		config: config,
+	}
 }
 
-func (m *mockOpenAIClient) CreateChatCompletionStream(ctx context.Context, req openai.ChatCompletionRequest) (*openai.ChatCompletionStream, error) {
-	return m.createChatCompletionStreamFunc(ctx, req)
+// decodeChatRequest reads the chat completion request sent to the server
+func decodeChatRequest(t *testing.T, r *http.Request) openai.ChatCompletionRequest {
+	t.Helper()
+	var req openai.ChatCompletionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		t.Errorf("failed to decode request: %v", err)
+	}
+	return req
 }
 
 func TestOpenAIComplete(t *testing.T) {
@@ -334,104 +335,92 @@ func TestOpenAIComplete(t *testing.T) {
 		MaxTokens:   100,
 	}
 
-	mockClient := &mockOpenAIClient{
-		createChatCompletionFunc: func(ctx context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
-			if req.Model != config.Model {
-				t.Errorf("unexpected model: got %v want %v", req.Model, config.Model)
-			}
-			if len(req.Messages) != 1 || req.Messages[0].Content != "test prompt" {
-				t.Errorf("unexpected messages: %v", req.Messages)
-			}
-			return openai.ChatCompletionResponse{
-				Choices: []openai.ChatCompletionChoice{
-					{Message: openai.ChatCompletionMessage{Content: "test response"}},
-				},
-			}, nil
-		},
-	}
+	fail := false
+	provider := newTestOpenAI(t, config, func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error": {"message": "api error"}}`))
+			return
+		}
 
-	provider := &OpenAI{
-		client: mockClient,
-		config: config,
-	}
+		req := decodeChatRequest(t, r)
+		if req.Model != config.Model {
+			t.Errorf("unexpected model: got %v want %v", req.Model, config.Model)
+		}
+		if len(req.Messages) != 1 || req.Messages[0].Content != "test prompt" {
+			t.Errorf("unexpected messages: %v", req.Messages)
+		}
+		json.NewEncoder(w).Encode(openai.ChatCompletionResponse{
+			Choices: []openai.ChatCompletionChoice{
+				{Message: openai.ChatCompletionMessage{Content: "test response"}},
+			},
+		})
+	})
 
 	ctx := context.Background()
-	got, err := provider.Complete(ctx, "test prompt")
+	got, err := provider.Complete(ctx, NewRequest("test prompt"))
 	if err != nil {
 		t.Fatalf("Complete() error = %v", err)
 	}
-	if want := "test response"; got != want {
-		t.Errorf("Complete() = %v, want %v", got, want)
+	if want := "test response"; got.Content != want {
+		t.Errorf("Complete() = %v, want %v", got.Content, want)
 	}
 
 	// Test error case
-	mockClient.createChatCompletionFunc = func(ctx context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
-		return openai.ChatCompletionResponse{}, errors.New("api error")
-	}
-	if _, err := provider.Complete(ctx, "test prompt"); err == nil {
+	fail = true
+	if _, err := provider.Complete(ctx, NewRequest("test prompt")); err == nil {
 		t.Error("Complete() expected error")
 	}
 }
 
-type mockChatCompletionStream struct {
-	recvFunc  func() (openai.ChatCompletionStreamResponse, error)
-	closeFunc func() error
-}
+func TestOpenAIStream(t *testing.T) {
+	config := OpenAIConfig{
+		APIKey:      "test-key",
+		Model:       "gpt-3.5-turbo",
+		Temperature: 0.7,
+		MaxTokens:   100,
+	}
 
-func (m *mockChatCompletionStream) Recv() (openai.ChatCompletionStreamResponse, error) {
-	return m.recvFunc()
-}
+	fail := false
+	provider := newTestOpenAI(t, config, func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error": {"message": "stream error"}}`))
+			return
+		}
 
-func (m *mockChatCompletionStream) Close() error {
-	return m.closeFunc()
-}
+		req := decodeChatRequest(t, r)
+		if req.Model != "gpt-3.5-turbo" {
+			t.Errorf("unexpected model: got %v want gpt-3.5-turbo", req.Model)
+		}
+		if len(req.Messages) != 1 || req.Messages[0].Content != "test prompt" {
+			t.Errorf("unexpected messages: %v", req.Messages)
+		}
 
-func TestOpenAIStream(t *testing.T) {
-	mockStream := &mockChatCompletionStream{
-		recvFunc: func() (openai.ChatCompletionStreamResponse, error) {
-			return openai.ChatCompletionStreamResponse{
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, token := range []string{"test", " stream"} {
+			chunk, _ := json.Marshal(openai.ChatCompletionStreamResponse{
 				Choices: []openai.ChatCompletionStreamChoice{
-					{Delta: openai.ChatCompletionStreamChoiceDelta{Content: "test"}},
+					{Delta: openai.ChatCompletionStreamChoiceDelta{Content: token}},
 				},
-			}, nil
-		},
-		closeFunc: func() error { return nil },
-	}
+			})
+			fmt.Fprintf(w, "data: %s\n\n", chunk)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	})
 
-	mockClient := &mockOpenAIClient{
-		createChatCompletionStreamFunc: func(ctx context.Context, req openai.ChatCompletionRequest) (*openai.ChatCompletionStream, error) {
-			if req.Model != "gpt-3.5-turbo" {
-				t.Errorf("unexpected model: got %v want gpt-3.5-turbo", req.Model)
-			}
-			if len(req.Messages) != 1 || req.Messages[0].Content != "test prompt" {
-				t.Errorf("unexpected messages: %v", req.Messages)
-			}
-			return &openai.ChatCompletionStream{}, nil
-		},
+	ctx := context.Background()
+	tokens, err := collectOpenAIStream(provider.Stream(ctx, NewRequest("test prompt")))
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
 	}
-
-	provider := &OpenAI{
-		client: mockClient,
-		config: openai.DefaultConfig("test-key"),
+	if got := strings.Join(tokens, ""); got != "test stream" {
+		t.Errorf("Stream() = %q, want %q", got, "test stream")
 	}
 
-	ctx := context.Background()
-	ch := make(chan string)
-	errCh := make(chan error)
-
-	go provider.Stream(ctx, "test prompt", "gpt-3.5-turbo", 0.7, 100, ch, errCh)
-
 	// Test error case
-	mockClient.createChatCompletionStreamFunc = func(ctx context.Context, req openai.ChatCompletionRequest) (*openai.ChatCompletionStream, error) {
-		return nil, errors.New("stream error")
-	}
-	go provider.Stream(ctx, "test prompt", "gpt-3.5-turbo", 0.7, 100, ch, errCh)
-	select {
-	case err := <-errCh:
-		if err == nil {
-			t.Error("Stream() expected error")
-		}
-	case <-ch:
-		t.Error("Stream() unexpected response")
+	fail = true
+	if _, err := collectOpenAIStream(provider.Stream(ctx, NewRequest("test prompt"))); err == nil {
+		t.Error("Stream() expected error")
 	}
 }
@@ -0,0 +1,76 @@
+package provider
+
+import "strings"
+
+// Price is the cost of a model in USD per million tokens
+type Price struct {
+	Input  float64
+	Output float64
+}
+
+// Pricing maps model names to prices. Besides exact names, lookups match
+// snapshots of a model: "gpt-4o" also prices "gpt-4o-2024-08-06" and
+// "claude-3-5-haiku" prices "claude-3-5-haiku-latest", but "gpt-4" does not
+// price "gpt-4o" or "gpt-4.1", which need entries of their own.
+type Pricing map[string]Price
+
+// DefaultPricing holds list prices for common models. Prices change; pass
+// your own table to the generator when accuracy matters.
+var DefaultPricing = Pricing{
+	"gpt-4.1":             {Input: 2.00, Output: 8.00},
+	"gpt-4.1-mini":        {Input: 0.40, Output: 1.60},
+	"gpt-4.1-nano":        {Input: 0.10, Output: 0.40},
+	"gpt-4o":              {Input: 2.50, Output: 10.00},
+	"gpt-4o-mini":         {Input: 0.15, Output: 0.60},
+	"gpt-4-turbo":         {Input: 10.00, Output: 30.00},
+	"gpt-4-turbo-preview": {Input: 10.00, Output: 30.00},
+	"gpt-4-0125-preview":  {Input: 10.00, Output: 30.00},
+	"gpt-4-1106-preview":  {Input: 10.00, Output: 30.00},
+	"gpt-4":               {Input: 30.00, Output: 60.00},
+	"gpt-3.5-turbo":       {Input: 0.50, Output: 1.50},
+
+	"claude-3-7-sonnet": {Input: 3.00, Output: 15.00},
+	"claude-3-5-sonnet": {Input: 3.00, Output: 15.00},
+	"claude-3-5-haiku":  {Input: 0.80, Output: 4.00},
+	"claude-3-opus":     {Input: 15.00, Output: 75.00},
+	"claude-3-haiku":    {Input: 0.25, Output: 1.25},
+}
+
+// Lookup returns the price for model: an exact entry, or else the longest
+// entry that model is a snapshot of. It reports false for unknown models.
+func (p Pricing) Lookup(model string) (Price, bool) {
+	if price, ok := p[model]; ok {
+		return price, true
+	}
+
+	var best string
+	for name := range p {
+		if isSnapshot(model, name) && len(name) > len(best) {
+			best = name
+		}
+	}
+	if best == "" {
+		return Price{}, false
+	}
+	return p[best], true
+}
+
+// isSnapshot reports whether model is a dated or versioned snapshot of name,
+// such as "gpt-4o-2024-08-06", or its "-latest" alias
+func isSnapshot(model, name string) bool {
+	suffix, ok := strings.CutPrefix(model, name+"-")
+	if !ok || suffix == "" {
+		return false
+	}
+	return suffix == "latest" || (suffix[0] >= '0' && suffix[0] <= '9')
+}
+
+// Cost estimates the cost in USD of the given usage on model. It reports
+// false when the model has no known price.
+func (p Pricing) Cost(model string, usage Usage) (float64, bool) {
+	price, ok := p.Lookup(model)
+	if !ok {
+		return 0, false
+	}
+	return (float64(usage.PromptTokens)*price.Input + float64(usage.CompletionTokens)*price.Output) / 1_000_000, true
+}
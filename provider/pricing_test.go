@@ -0,0 +1,45 @@
+package provider
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPricing(t *testing.T) {
+	tests := []struct {
+		model  string
+		want   float64
+		wantOK bool
+	}{
+		{model: "gpt-4o", want: 2.50 + 10.00, wantOK: true},
+		{model: "gpt-4o-2024-08-06", want: 2.50 + 10.00, wantOK: true},
+		{model: "gpt-4o-mini", want: 0.15 + 0.60, wantOK: true},
+		{model: "gpt-4o-mini-2024-07-18", want: 0.15 + 0.60, wantOK: true},
+		{model: "gpt-4-turbo-preview", want: 10.00 + 30.00, wantOK: true},
+		{model: "gpt-4-turbo-2024-04-09", want: 10.00 + 30.00, wantOK: true},
+		{model: "gpt-4-0125-preview", want: 10.00 + 30.00, wantOK: true},
+		{model: "gpt-4-0613", want: 30.00 + 60.00, wantOK: true},
+		{model: "gpt-4.1", want: 2.00 + 8.00, wantOK: true},
+		{model: "gpt-4.1-mini", want: 0.40 + 1.60, wantOK: true},
+		{model: "gpt-4.1-mini-2025-04-14", want: 0.40 + 1.60, wantOK: true},
+		{model: "claude-3-5-haiku-latest", want: 0.80 + 4.00, wantOK: true},
+		{model: "claude-3-5-sonnet-20241022", want: 3.00 + 15.00, wantOK: true},
+		{model: "gpt-4-vision-preview", wantOK: false},
+		{model: "gpt-4.5-preview", wantOK: false},
+		{model: "gpt-4-", wantOK: false},
+		{model: "unknown-model", wantOK: false},
+	}
+
+	usage := Usage{PromptTokens: 1_000_000, CompletionTokens: 1_000_000}
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			got, ok := DefaultPricing.Cost(tt.model, usage)
+			if ok != tt.wantOK {
+				t.Fatalf("Cost() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Cost() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,20 +2,276 @@ package provider
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 )
 
 // Provider defines the interface for AI providers
 type Provider interface {
-	// Complete generates a completion for the given prompt
-	Complete(ctx context.Context, prompt string) (string, error)
+	// Complete generates a completion for the given request
+	Complete(ctx context.Context, req Request) (Response, error)
 
 	// Stream generates a completion and streams the response
-	Stream(ctx context.Context, prompt string) (<-chan string, <-chan error, error)
+	Stream(ctx context.Context, req Request) (<-chan string, <-chan error, error)
+}
+
+// Capabilities describes optional features a provider supports natively
+type Capabilities struct {
+	// JSONSchema reports that responses are constrained to Request.Schema
+	JSONSchema bool
+	// JSONMode reports that responses are constrained to valid JSON,
+	// without enforcing a particular schema
+	JSONMode bool
+}
+
+// CapabilityProvider is implemented by providers that support optional features
+type CapabilityProvider interface {
+	Capabilities() Capabilities
+}
+
+// CapabilitiesOf reports the capabilities of p. Providers that do not
+// implement CapabilityProvider support no optional features.
+func CapabilitiesOf(p Provider) Capabilities {
+	if c, ok := p.(CapabilityProvider); ok {
+		return c.Capabilities()
+	}
+	return Capabilities{}
+}
+
+// Named is implemented by providers that report a name, such as "openai"
+type Named interface {
+	Name() string
+}
+
+// NameOf returns the name of p, or its type if it does not implement Named
+func NameOf(p Provider) string {
+	if n, ok := p.(Named); ok {
+		return n.Name()
+	}
+	return fmt.Sprintf("%T", p)
+}
+
+// Configured is implemented by providers with default generation
+// parameters, such as the model used when a request does not set one
+type Configured interface {
+	Defaults() Params
+}
+
+// DefaultsOf returns the default parameters of p, or no parameters if it does
+// not implement Configured
+func DefaultsOf(p Provider) Params {
+	if c, ok := p.(Configured); ok {
+		return c.Defaults()
+	}
+	return Params{}
+}
+
+// Role identifies the author of a message
+type Role string
+
+// Supported message roles
+const (
+	RoleSystem    Role = "system"
+	RoleUser      Role = "user"
+	RoleAssistant Role = "assistant"
+	RoleTool      Role = "tool"
+)
+
+// Message is a single turn of a conversation
+type Message struct {
+	Role    Role
+	Content string
+
+	// ToolCalls are the calls requested by an assistant message
+	ToolCalls []ToolCall
+	// ToolCallID identifies the call a tool message answers
+	ToolCallID string
+}
+
+// Tool describes a function the model may call
+type Tool struct {
+	Name        string
+	Description string
+	// Parameters is the JSON Schema of the function's arguments
+	Parameters json.RawMessage
+}
+
+// ToolCall is a request from the model to call a tool
+type ToolCall struct {
+	ID   string
+	Name string
+	// Arguments are the JSON encoded arguments
+	Arguments string
+}
+
+// Request holds everything a provider needs to generate a completion
+type Request struct {
+	Messages []Message
+	Params   Params
+
+	// Schema is the JSON Schema the response must conform to, if known.
+	// Providers reporting the JSONSchema or JSONMode capability use it to
+	// constrain generation; others rely on the instructions in the prompt.
+	Schema json.RawMessage
+
+	// Tools are the functions the model may call instead of answering
+	Tools []Tool
+}
+
+// Response is a completed generation along with its metadata
+type Response struct {
+	Content      string
+	Model        string
+	FinishReason string
+	Usage        Usage
+
+	// ToolCalls are the tools the model asked to call, if any
+	ToolCalls []ToolCall
+
+	// Provider is the name of the provider that answered, when a wrapper
+	// such as Fallback chooses between several
+	Provider string
+}
+
+// Usage reports the tokens consumed by a request
+type Usage struct {
+	PromptTokens     int
+	CompletionTokens int
+	TotalTokens      int
+}
+
+// Add returns the sum of two usages
+func (u Usage) Add(other Usage) Usage {
+	return Usage{
+		PromptTokens:     u.PromptTokens + other.PromptTokens,
+		CompletionTokens: u.CompletionTokens + other.CompletionTokens,
+		TotalTokens:      u.TotalTokens + other.TotalTokens,
+	}
+}
+
+// Params controls how a completion is generated. Unset fields fall back to
+// the provider's configured defaults.
+type Params struct {
+	Model            string
+	Temperature      *float64
+	TopP             *float64
+	MaxTokens        int
+	Stop             []string
+	Seed             *int
+	PresencePenalty  *float64
+	FrequencyPenalty *float64
+}
+
+// Merge returns a copy of p with every field set in override replacing its own
+func (p Params) Merge(override Params) Params {
+	if override.Model != "" {
+		p.Model = override.Model
+	}
+	if override.Temperature != nil {
+		p.Temperature = override.Temperature
+	}
+	if override.TopP != nil {
+		p.TopP = override.TopP
+	}
+	if override.MaxTokens != 0 {
+		p.MaxTokens = override.MaxTokens
+	}
+	if override.Stop != nil {
+		p.Stop = override.Stop
+	}
+	if override.Seed != nil {
+		p.Seed = override.Seed
+	}
+	if override.PresencePenalty != nil {
+		p.PresencePenalty = override.PresencePenalty
+	}
+	if override.FrequencyPenalty != nil {
+		p.FrequencyPenalty = override.FrequencyPenalty
+	}
+	return p
+}
+
+// Float64 returns a pointer to v, for setting optional Params fields
+func Float64(v float64) *float64 {
+	return &v
+}
+
+// configFloat64 returns a pointer to a configured value, or nil when the
+// field was left at zero so that the API's own default applies
+func configFloat64(v float64) *float64 {
+	if v == 0 {
+		return nil
+	}
+	return &v
+}
+
+// Int returns a pointer to v, for setting optional Params fields
+func Int(v int) *int {
+	return &v
+}
+
+// NewRequest creates a request with a single user message
+func NewRequest(prompt string) Request {
+	return Request{Messages: []Message{{Role: RoleUser, Content: prompt}}}
+}
+
+// Prompt returns the content of all messages as a single string, for
+// providers and tools that only deal in plain text
+func (r Request) Prompt() string {
+	parts := make([]string, len(r.Messages))
+	for i, m := range r.Messages {
+		parts[i] = m.Content
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// Hash returns a hex digest identifying the request by its messages,
+// parameters, schema and tools
+func (r Request) Hash() string {
+	data, err := json.Marshal(r)
+	if err != nil {
+		// Only reachable with an invalid schema, which no provider accepts
+		// either; fall back to the prompt so that the hash stays usable
+		data = []byte(r.Prompt())
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
 }
 
 // Common provider errors
 var (
 	ErrRateLimit     = errors.New("rate limit exceeded")
 	ErrContextLength = errors.New("context length exceeded")
+	ErrServer        = errors.New("provider server error")
+	ErrCircuitOpen   = errors.New("circuit breaker open")
 )
+
+// RetryAfterError wraps a provider error with the server's hint for when
+// the request may be retried
+type RetryAfterError struct {
+	Err   error
+	After time.Duration
+}
+
+func (e *RetryAfterError) Error() string {
+	return fmt.Sprintf("%v (retry after %s)", e.Err, e.After)
+}
+
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
+
+// RetryAfter extracts a Retry-After hint from err, if one is present
+func RetryAfter(err error) (time.Duration, bool) {
+	var rerr *RetryAfterError
+	if errors.As(err, &rerr) {
+		return rerr.After, true
+	}
+	return 0, false
+}
@@ -0,0 +1,542 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+func TestRequestPrompt(t *testing.T) {
+	if got := NewRequest("hello").Prompt(); got != "hello" {
+		t.Errorf("Prompt() = %q, want %q", got, "hello")
+	}
+
+	req := Request{Messages: []Message{
+		{Role: RoleSystem, Content: "Be brief."},
+		{Role: RoleUser, Content: "Hi"},
+	}}
+	if got, want := req.Prompt(), "Be brief.\n\nHi"; got != want {
+		t.Errorf("Prompt() = %q, want %q", got, want)
+	}
+}
+
+func TestOpenAIChatRequest(t *testing.T) {
+	o := &OpenAI{config: OpenAIConfig{Model: "gpt-4", Temperature: 0.5, MaxTokens: 10}}
+
+	got := o.chatRequest(Request{Messages: []Message{
+		{Role: RoleSystem, Content: "Be brief."},
+		{Role: RoleUser, Content: "Hi"},
+		{Role: RoleAssistant, Content: "Hello"},
+	}})
+
+	wantRoles := []string{openai.ChatMessageRoleSystem, openai.ChatMessageRoleUser, openai.ChatMessageRoleAssistant}
+	if len(got.Messages) != len(wantRoles) {
+		t.Fatalf("expected %d messages, got %d", len(wantRoles), len(got.Messages))
+	}
+	for i, role := range wantRoles {
+		if got.Messages[i].Role != role {
+			t.Errorf("message %d role = %q, want %q", i, got.Messages[i].Role, role)
+		}
+	}
+	if got.Model != "gpt-4" || got.MaxTokens != 10 {
+		t.Errorf("unexpected request config: %+v", got)
+	}
+}
+
+func TestOpenAIChatRequestResponseFormat(t *testing.T) {
+	schema := json.RawMessage(`{"type":"object"}`)
+
+	tests := []struct {
+		format   string
+		schema   json.RawMessage
+		wantType openai.ChatCompletionResponseFormatType
+		wantCaps Capabilities
+	}{
+		{format: "", schema: schema},
+		{format: OpenAIFormatJSON, schema: schema, wantType: openai.ChatCompletionResponseFormatTypeJSONObject, wantCaps: Capabilities{JSONMode: true}},
+		{format: OpenAIFormatJSONSchema, schema: schema, wantType: openai.ChatCompletionResponseFormatTypeJSONSchema, wantCaps: Capabilities{JSONSchema: true, JSONMode: true}},
+		{format: OpenAIFormatJSONSchema, wantCaps: Capabilities{JSONSchema: true, JSONMode: true}},
+	}
+
+	for _, tt := range tests {
+		o := &OpenAI{config: OpenAIConfig{Model: "gpt-4o", ResponseFormat: tt.format}}
+		if got := CapabilitiesOf(o); got != tt.wantCaps {
+			t.Errorf("format %q: capabilities = %+v, want %+v", tt.format, got, tt.wantCaps)
+		}
+
+		req := NewRequest("Hi")
+		req.Schema = tt.schema
+		got := o.chatRequest(req).ResponseFormat
+
+		if tt.wantType == "" {
+			if got != nil {
+				t.Errorf("format %q: unexpected response format %+v", tt.format, got)
+			}
+			continue
+		}
+		if got == nil || got.Type != tt.wantType {
+			t.Errorf("format %q: response format = %+v, want type %q", tt.format, got, tt.wantType)
+			continue
+		}
+		if tt.wantType == openai.ChatCompletionResponseFormatTypeJSONSchema {
+			if got.JSONSchema == nil || got.JSONSchema.Name == "" {
+				t.Fatalf("format %q: missing json_schema: %+v", tt.format, got)
+			}
+			data, _ := got.JSONSchema.Schema.MarshalJSON()
+			if string(data) != string(schema) {
+				t.Errorf("format %q: schema = %s, want %s", tt.format, data, schema)
+			}
+		}
+	}
+
+	if _, err := NewOpenAI(OpenAIConfig{APIKey: "key", ResponseFormat: "yaml"}); err == nil {
+		t.Error("expected error for unknown response format")
+	}
+	if caps := CapabilitiesOf(&MockProvider{}); caps != (Capabilities{}) {
+		t.Errorf("mock capabilities = %+v, want none", caps)
+	}
+}
+
+func TestDefaultsOf(t *testing.T) {
+	openAI, err := NewOpenAI(OpenAIConfig{APIKey: "test-key", Model: "gpt-4o", Temperature: 0.2, MaxTokens: 100})
+	if err != nil {
+		t.Fatalf("NewOpenAI() error = %v", err)
+	}
+
+	want := Params{Model: "gpt-4o", Temperature: Float64(0.2), MaxTokens: 100}
+	for _, p := range []Provider{
+		openAI,
+		Limited(openAI, Limits{}),
+		CircuitBreaker(openAI, BreakerConfig{}),
+		Fallback(openAI, &MockProvider{}),
+	} {
+		got := DefaultsOf(p)
+		if got.Model != want.Model || got.MaxTokens != want.MaxTokens || got.Temperature == nil || *got.Temperature != *want.Temperature {
+			t.Errorf("DefaultsOf(%T) = %+v, want %+v", p, got, want)
+		}
+	}
+
+	if got := DefaultsOf(&MockProvider{}); got.Model != "" || got.Temperature != nil {
+		t.Errorf("providers without defaults should report none, got %+v", got)
+	}
+}
+
+func TestParamsMerge(t *testing.T) {
+	base := Params{Model: "gpt-4", Temperature: Float64(0.7), MaxTokens: 100}
+	got := base.Merge(Params{Temperature: Float64(0), Stop: []string{"\n"}})
+
+	if got.Model != "gpt-4" || got.MaxTokens != 100 {
+		t.Errorf("unset fields should be preserved, got %+v", got)
+	}
+	if *got.Temperature != 0 {
+		t.Errorf("temperature = %v, want 0", *got.Temperature)
+	}
+	if len(got.Stop) != 1 {
+		t.Errorf("stop = %v, want [\\n]", got.Stop)
+	}
+	if *base.Temperature != 0.7 {
+		t.Error("Merge should not modify the receiver")
+	}
+}
+
+func TestOpenAIChatRequestParams(t *testing.T) {
+	o := &OpenAI{config: OpenAIConfig{Model: "gpt-4", Temperature: 0.7, MaxTokens: 2000}}
+
+	got := o.chatRequest(Request{
+		Messages: []Message{{Role: RoleUser, Content: "Hi"}},
+		Params: Params{
+			Model:            "gpt-4o",
+			Temperature:      Float64(0),
+			TopP:             Float64(0.9),
+			Stop:             []string{"END"},
+			Seed:             Int(7),
+			PresencePenalty:  Float64(0.5),
+			FrequencyPenalty: Float64(-0.5),
+		},
+	})
+
+	if got.Model != "gpt-4o" {
+		t.Errorf("model = %q, want gpt-4o", got.Model)
+	}
+	if got.Temperature == 0 || got.Temperature > 0.0001 {
+		t.Errorf("zero temperature should be sent as a tiny non-zero value, got %v", got.Temperature)
+	}
+	if got.MaxTokens != 2000 {
+		t.Errorf("max tokens = %d, want provider default 2000", got.MaxTokens)
+	}
+	if got.TopP != 0.9 || got.PresencePenalty != 0.5 || got.FrequencyPenalty != -0.5 {
+		t.Errorf("unexpected sampling params: %+v", got)
+	}
+	if got.Seed == nil || *got.Seed != 7 || len(got.Stop) != 1 {
+		t.Errorf("unexpected seed or stop: %+v", got)
+	}
+}
+
+func TestUnsetTemperature(t *testing.T) {
+	o := &OpenAI{config: OpenAIConfig{Model: "gpt-4o"}}
+	body, err := json.Marshal(o.chatRequest(NewRequest("Hi")))
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if strings.Contains(string(body), "temperature") {
+		t.Errorf("unset temperature should be left to the API default, got %s", body)
+	}
+
+	for _, p := range []Provider{
+		o,
+		&Anthropic{config: AnthropicConfig{Model: "claude-3-5-haiku-latest"}},
+		&Ollama{config: OllamaConfig{Model: "llama3.2"}},
+	} {
+		if got := DefaultsOf(p).Temperature; got != nil {
+			t.Errorf("DefaultsOf(%T).Temperature = %v, want nil", p, *got)
+		}
+	}
+}
+
+// chatCompletionBody is a minimal chat completion response
+const chatCompletionBody = `{
+	"id": "chatcmpl-1",
+	"object": "chat.completion",
+	"model": "llama-3.1-8b",
+	"choices": [{"index": 0, "message": {"role": "assistant", "content": "Hello"}, "finish_reason": "stop"}],
+	"usage": {"prompt_tokens": 5, "completion_tokens": 1, "total_tokens": 6}
+}`
+
+// countingTransport counts the requests sent through it
+type countingTransport struct {
+	requests int
+}
+
+func (c *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.requests++
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestOpenAICompatibleEndpoint(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer test-key" {
+			t.Errorf("Authorization = %q", got)
+		}
+		if got := r.Header.Get("OpenAI-Organization"); got != "org-test" {
+			t.Errorf("OpenAI-Organization = %q", got)
+		}
+		if got := r.Header.Get("X-Team"); got != "search" {
+			t.Errorf("X-Team = %q", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(chatCompletionBody))
+	}))
+	defer server.Close()
+
+	transport := &countingTransport{}
+	p, err := NewOpenAI(OpenAIConfig{
+		APIKey:       "test-key",
+		Model:        "llama-3.1-8b",
+		BaseURL:      server.URL + "/v1/",
+		Organization: "org-test",
+		Headers:      http.Header{"X-Team": {"search"}},
+		HTTPClient:   &http.Client{Transport: transport},
+	})
+	if err != nil {
+		t.Fatalf("NewOpenAI() error = %v", err)
+	}
+
+	resp, err := p.Complete(context.Background(), NewRequest("Hi"))
+	if err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if resp.Content != "Hello" || resp.Usage.TotalTokens != 6 {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+	if transport.requests != 1 {
+		t.Errorf("expected the custom HTTP client to be used, got %d requests", transport.requests)
+	}
+}
+
+func TestOpenAIStreamCancel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for i := 0; i < 100; i++ {
+			fmt.Fprint(w, `data: {"choices": [{"delta": {"content": "token"}}]}`+"\n\n")
+		}
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	p, err := NewOpenAI(OpenAIConfig{APIKey: "test-key", Model: "gpt-4o", BaseURL: server.URL + "/v1"})
+	if err != nil {
+		t.Fatalf("NewOpenAI() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	content, errs, err := p.Stream(ctx, NewRequest("Hi"))
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+	if chunk := <-content; chunk != "token" {
+		t.Fatalf("first chunk = %q", chunk)
+	}
+
+	// The stream shuts down once cancelled, even if nobody reads it anymore
+	cancel()
+	select {
+	case err := <-errs:
+		if err == nil {
+			t.Error("expected an error after cancellation")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("stream did not stop after cancellation")
+	}
+}
+
+func TestOpenAIAzure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/openai/deployments/prod-gpt4o/chat/completions" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("api-version"); got != "2024-06-01" {
+			t.Errorf("api-version = %q", got)
+		}
+		if got := r.Header.Get("api-key"); got != "azure-key" {
+			t.Errorf("api-key = %q", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(chatCompletionBody))
+	}))
+	defer server.Close()
+
+	p, err := NewOpenAI(OpenAIConfig{
+		APIKey:      "azure-key",
+		Model:       "gpt-4o",
+		BaseURL:     server.URL,
+		Azure:       true,
+		APIVersion:  "2024-06-01",
+		Deployments: map[string]string{"gpt-4o": "prod-gpt4o"},
+	})
+	if err != nil {
+		t.Fatalf("NewOpenAI() error = %v", err)
+	}
+
+	if _, err := p.Complete(context.Background(), NewRequest("Hi")); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+}
+
+func TestNewOpenAIConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  OpenAIConfig
+		wantErr bool
+	}{
+		{name: "missing api key", config: OpenAIConfig{Model: "gpt-4o"}, wantErr: true},
+		{name: "local server without api key", config: OpenAIConfig{BaseURL: "http://localhost:8000/v1"}},
+		{name: "azure without api key", config: OpenAIConfig{BaseURL: "https://example.openai.azure.com", Azure: true}, wantErr: true},
+		{name: "azure without base url", config: OpenAIConfig{APIKey: "key", Azure: true}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewOpenAI(tt.config); (err != nil) != tt.wantErr {
+				t.Errorf("NewOpenAI() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOpenAIErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		retryAfter string
+		body       string
+		want       error
+		wantAfter  time.Duration
+	}{
+		{
+			name:   "rate limit",
+			status: http.StatusTooManyRequests,
+			body:   `{"error": {"message": "Rate limit reached", "type": "requests", "code": "rate_limit_exceeded"}}`,
+			want:   ErrRateLimit,
+		},
+		{
+			name:       "rate limit with retry after",
+			status:     http.StatusTooManyRequests,
+			retryAfter: "7",
+			body:       `{"error": {"message": "Rate limit reached", "type": "requests", "code": "rate_limit_exceeded"}}`,
+			want:       ErrRateLimit,
+			wantAfter:  7 * time.Second,
+		},
+		{
+			name:   "server error",
+			status: http.StatusInternalServerError,
+			body:   `{"error": {"message": "The server had an error while processing your request", "type": "server_error"}}`,
+			want:   ErrServer,
+		},
+		{
+			name:       "unavailable with retry after",
+			status:     http.StatusServiceUnavailable,
+			retryAfter: "30",
+			body:       `{"error": {"message": "Overloaded", "type": "server_error"}}`,
+			want:       ErrServer,
+			wantAfter:  30 * time.Second,
+		},
+		{
+			name:   "bad gateway without json body",
+			status: http.StatusBadGateway,
+			body:   `<html>502 Bad Gateway</html>`,
+			want:   ErrServer,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			p, err := NewOpenAI(OpenAIConfig{APIKey: "test-key", Model: "gpt-4o", BaseURL: server.URL})
+			if err != nil {
+				t.Fatalf("NewOpenAI() error = %v", err)
+			}
+
+			_, err = p.Complete(context.Background(), NewRequest("Hi"))
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Complete() error = %v, want %v", err, tt.want)
+			}
+			if after, ok := RetryAfter(err); ok != (tt.wantAfter > 0) || after != tt.wantAfter {
+				t.Errorf("Complete() RetryAfter() = %v, %v, want %v", after, ok, tt.wantAfter)
+			}
+			_, _, err = p.Stream(context.Background(), NewRequest("Hi"))
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Stream() error = %v, want %v", err, tt.want)
+			}
+			if after, ok := RetryAfter(err); ok != (tt.wantAfter > 0) || after != tt.wantAfter {
+				t.Errorf("Stream() RetryAfter() = %v, %v, want %v", after, ok, tt.wantAfter)
+			}
+		})
+	}
+}
+
+func TestOpenAITools(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body openai.ChatCompletionRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("invalid request body: %v", err)
+		}
+
+		if len(body.Tools) != 1 || body.Tools[0].Type != openai.ToolTypeFunction || body.Tools[0].Function.Name != "get_weather" {
+			t.Errorf("unexpected tools: %+v", body.Tools)
+		}
+		if len(body.Messages) != 3 {
+			t.Fatalf("expected 3 messages, got %+v", body.Messages)
+		}
+		if calls := body.Messages[1].ToolCalls; len(calls) != 1 || calls[0].ID != "call_0" || calls[0].Function.Arguments != `{"city":"Oslo"}` {
+			t.Errorf("unexpected assistant tool calls: %+v", calls)
+		}
+		if m := body.Messages[2]; m.Role != openai.ChatMessageRoleTool || m.ToolCallID != "call_0" {
+			t.Errorf("unexpected tool message: %+v", m)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"model": "gpt-4o",
+			"choices": [{
+				"index": 0,
+				"message": {
+					"role": "assistant",
+					"content": null,
+					"tool_calls": [{"id": "call_1", "type": "function", "function": {"name": "get_weather", "arguments": "{\"city\":\"Paris\"}"}}]
+				},
+				"finish_reason": "tool_calls"
+			}],
+			"usage": {"prompt_tokens": 50, "completion_tokens": 10, "total_tokens": 60}
+		}`))
+	}))
+	defer server.Close()
+
+	p, err := NewOpenAI(OpenAIConfig{APIKey: "test-key", Model: "gpt-4o", BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("NewOpenAI() error = %v", err)
+	}
+
+	resp, err := p.Complete(context.Background(), Request{
+		Messages: []Message{
+			{Role: RoleUser, Content: "Weather in Oslo and Paris?"},
+			{Role: RoleAssistant, ToolCalls: []ToolCall{{ID: "call_0", Name: "get_weather", Arguments: `{"city":"Oslo"}`}}},
+			{Role: RoleTool, ToolCallID: "call_0", Content: `{"temperature":4}`},
+		},
+		Tools: []Tool{{
+			Name:        "get_weather",
+			Description: "Get the current temperature in a city",
+			Parameters:  json.RawMessage(`{"type":"object","properties":{"city":{"type":"string"}}}`),
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+
+	want := []ToolCall{{ID: "call_1", Name: "get_weather", Arguments: `{"city":"Paris"}`}}
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0] != want[0] {
+		t.Errorf("tool calls = %+v, want %+v", resp.ToolCalls, want)
+	}
+	if resp.FinishReason != "tool_calls" {
+		t.Errorf("finish reason = %q", resp.FinishReason)
+	}
+}
+
+func TestMockProviderConcurrent(t *testing.T) {
+	const calls = 20
+	mock := &MockProvider{Response: "default"}
+	for i := 0; i < calls/2; i++ {
+		mock.Errors = append(mock.Errors, ErrRateLimit)
+		mock.Responses = append(mock.Responses, Response{Content: "queued"})
+	}
+
+	var wg sync.WaitGroup
+	results := make(chan error, calls)
+	for i := 0; i < calls; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := mock.Complete(context.Background(), NewRequest("Hi"))
+			if err == nil && resp.Content != "queued" {
+				err = errors.New("unexpected response " + resp.Content)
+			}
+			results <- err
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	// Every queued error and response is used exactly once
+	failures := 0
+	for err := range results {
+		if errors.Is(err, ErrRateLimit) {
+			failures++
+		} else if err != nil {
+			t.Error(err)
+		}
+	}
+	if failures != calls/2 || len(mock.Requests) != calls {
+		t.Errorf("got %d failures and %d requests, want %d and %d", failures, len(mock.Requests), calls/2, calls)
+	}
+}
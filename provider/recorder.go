@@ -0,0 +1,126 @@
+package provider
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// Recorder passes requests through to another provider and records every
+// successful exchange, including stream chunks, to a cassette file that a
+// Replayer can serve in tests. The file is rewritten after each exchange.
+type Recorder struct {
+	provider Provider
+	path     string
+
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+// NewRecorder records the exchanges with p to a new cassette at path,
+// replacing any existing recording
+func NewRecorder(p Provider, path string) *Recorder {
+	return &Recorder{
+		provider: p,
+		path:     path,
+		cassette: Cassette{Provider: NameOf(p), Capabilities: CapabilitiesOf(p)},
+	}
+}
+
+// Name returns the name of the wrapped provider
+func (r *Recorder) Name() string {
+	return NameOf(r.provider)
+}
+
+// Defaults returns the default parameters of the wrapped provider
+func (r *Recorder) Defaults() Params {
+	return DefaultsOf(r.provider)
+}
+
+// Capabilities returns the capabilities of the wrapped provider
+func (r *Recorder) Capabilities() Capabilities {
+	return CapabilitiesOf(r.provider)
+}
+
+// Complete completes the request with the wrapped provider and records it
+func (r *Recorder) Complete(ctx context.Context, req Request) (Response, error) {
+	resp, err := r.provider.Complete(ctx, req)
+	if err != nil {
+		return resp, err
+	}
+	if resp.Provider == "" {
+		resp.Provider = NameOf(r.provider)
+	}
+
+	if err := r.record(Interaction{Key: req.Hash(), Request: req, Response: resp}); err != nil {
+		return Response{}, err
+	}
+	return resp, nil
+}
+
+// Stream streams the request from the wrapped provider and records it once
+// the stream has ended successfully
+func (r *Recorder) Stream(ctx context.Context, req Request) (<-chan string, <-chan error, error) {
+	content, errs, err := r.provider.Stream(ctx, req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	out, outErrs := captureStream(ctx, content, errs, func(chunks []string) error {
+		resp := Response{Content: strings.Join(chunks, ""), Provider: NameOf(r.provider)}
+		return r.record(Interaction{Key: req.Hash(), Request: req, Response: resp, Chunks: chunks})
+	})
+	return out, outErrs, nil
+}
+
+// captureStream relays a stream and calls save with its chunks once it has
+// ended successfully
+func captureStream(ctx context.Context, content <-chan string, errs <-chan error, save func(chunks []string) error) (<-chan string, <-chan error) {
+	out := make(chan string)
+	outErrs := make(chan error, 1)
+
+	go func() {
+		defer close(out)
+		defer close(outErrs)
+
+		var chunks []string
+		for chunk := range content {
+			chunks = append(chunks, chunk)
+			select {
+			case out <- chunk:
+			case <-ctx.Done():
+				outErrs <- ctx.Err()
+				return
+			}
+		}
+		if err := <-errs; err != nil {
+			outErrs <- err
+			return
+		}
+
+		if err := save(chunks); err != nil {
+			outErrs <- err
+		}
+	}()
+
+	return out, outErrs
+}
+
+// Cassette returns a copy of the recording so far
+func (r *Recorder) Cassette() Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c := r.cassette
+	c.Interactions = append([]Interaction(nil), c.Interactions...)
+	return c
+}
+
+// record adds an interaction and saves the cassette
+func (r *Recorder) record(i Interaction) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cassette.Interactions = append(r.cassette.Interactions, i)
+	return r.cassette.Save(r.path)
+}
@@ -0,0 +1,234 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecordReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chain.json")
+	ctx := context.Background()
+
+	mock := &MockProvider{
+		Responses: []Response{
+			{Content: "Outline", Model: "gpt-4o"},
+			{Content: "Draft", Model: "gpt-4o"},
+			{Content: "Outline v2", Model: "gpt-4o"},
+		},
+		StreamTokens: []string{"Sum", "mary"},
+		Supports:     Capabilities{JSONMode: true},
+	}
+	rec := NewRecorder(mock, path)
+
+	outline := NewRequest("Outline a post about Go")
+	draft := NewRequest("Draft the post from: Outline")
+	for _, req := range []Request{outline, draft, outline} {
+		if _, err := rec.Complete(ctx, req); err != nil {
+			t.Fatalf("Complete() error = %v", err)
+		}
+	}
+	content, errs, err := rec.Stream(ctx, NewRequest("Summarize the post"))
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+	if chunks, err := collect(content, errs); err != nil || len(chunks) != 2 {
+		t.Fatalf("collect() = %v, %v", chunks, err)
+	}
+
+	rep, err := NewReplayer(path)
+	if err != nil {
+		t.Fatalf("NewReplayer() error = %v", err)
+	}
+	if rep.Name() != "mock" || rep.Capabilities() != mock.Supports {
+		t.Errorf("expected the recorded provider's name and capabilities, got %s %+v", rep.Name(), rep.Capabilities())
+	}
+
+	// Repeated requests get their recordings in order, then the last repeats
+	for _, want := range []string{"Outline", "Outline v2", "Outline v2"} {
+		resp, err := rep.Complete(ctx, outline)
+		if err != nil || resp.Content != want || resp.Provider != "mock" {
+			t.Errorf("Complete(outline) = %+v, %v, want %q", resp, err, want)
+		}
+	}
+	if resp, _ := rep.Complete(ctx, draft); resp.Content != "Draft" {
+		t.Errorf("Complete(draft) = %q", resp.Content)
+	}
+
+	content, errs, err = rep.Stream(ctx, NewRequest("Summarize the post"))
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+	chunks, err := collect(content, errs)
+	if err != nil || strings.Join(chunks, "|") != "Sum|mary" {
+		t.Errorf("replayed stream = %v, %v", chunks, err)
+	}
+
+	// Streamed recordings can also be completed, and the other way around
+	if resp, _ := rep.Complete(ctx, NewRequest("Summarize the post")); resp.Content != "Summary" {
+		t.Errorf("Complete(summary) = %q", resp.Content)
+	}
+	content, errs, _ = rep.Stream(ctx, draft)
+	if chunks, _ := collect(content, errs); strings.Join(chunks, "") != "Draft" {
+		t.Errorf("Stream(draft) = %v", chunks)
+	}
+}
+
+func TestReplayerMiss(t *testing.T) {
+	ctx := context.Background()
+	recorded := NewRecorder(&MockProvider{Response: "Paris"}, filepath.Join(t.TempDir(), "c.json"))
+	recorded.Complete(ctx, NewRequest("Capital of France?"))
+
+	t.Run("strict", func(t *testing.T) {
+		rep := Replay(recorded.Cassette())
+
+		_, err := rep.Complete(ctx, NewRequest("Capital of Spain?"))
+		if !errors.Is(err, ErrNoRecording) || !strings.Contains(err.Error(), "Capital of Spain?") {
+			t.Errorf("error = %v, want ErrNoRecording naming the prompt", err)
+		}
+		if _, _, err := rep.Stream(ctx, NewRequest("Capital of Spain?")); !errors.Is(err, ErrNoRecording) {
+			t.Errorf("Stream() error = %v, want ErrNoRecording", err)
+		}
+
+		// Parameters are part of the match
+		req := NewRequest("Capital of France?")
+		req.Params.Model = "gpt-4o"
+		if _, err := rep.Complete(ctx, req); !errors.Is(err, ErrNoRecording) {
+			t.Errorf("error = %v, want ErrNoRecording", err)
+		}
+		if len(rep.Misses()) != 3 {
+			t.Errorf("Misses() = %d requests, want 3", len(rep.Misses()))
+		}
+	})
+
+	t.Run("strict ignores fallback", func(t *testing.T) {
+		rep := Replay(recorded.Cassette())
+		rep.Fallback = &MockProvider{Response: "I don't know"}
+
+		if _, err := rep.Complete(ctx, NewRequest("Capital of Spain?")); !errors.Is(err, ErrNoRecording) {
+			t.Errorf("error = %v, want ErrNoRecording", err)
+		}
+	})
+
+	t.Run("fallback", func(t *testing.T) {
+		rep := Replay(recorded.Cassette())
+		rep.Mode = ReplayFallback
+		rep.Fallback = &MockProvider{Response: "I don't know", StreamTokens: []string{"I don't", " know"}}
+
+		if resp, err := rep.Complete(ctx, NewRequest("Capital of Spain?")); err != nil || resp.Content != "I don't know" {
+			t.Errorf("Complete() = %+v, %v", resp, err)
+		}
+		content, errs, err := rep.Stream(ctx, NewRequest("Capital of Spain?"))
+		if err != nil {
+			t.Fatalf("Stream() error = %v", err)
+		}
+		if chunks, err := collect(content, errs); err != nil || strings.Join(chunks, "") != "I don't know" {
+			t.Errorf("collect() = %v, %v", chunks, err)
+		}
+		if resp, _ := rep.Complete(ctx, NewRequest("Capital of France?")); resp.Content != "Paris" {
+			t.Errorf("recorded requests should still be replayed, got %q", resp.Content)
+		}
+		if n := len(rep.Cassette().Interactions); n != 1 {
+			t.Errorf("cassette has %d interactions, want the recorded one only", n)
+		}
+	})
+
+	t.Run("fallback without provider", func(t *testing.T) {
+		rep := Replay(recorded.Cassette())
+		rep.Mode = ReplayFallback
+
+		_, err := rep.Complete(ctx, NewRequest("Capital of Spain?"))
+		if !errors.Is(err, ErrNoRecording) || !strings.Contains(err.Error(), "no fallback provider") {
+			t.Errorf("error = %v, want ErrNoRecording without a fallback", err)
+		}
+	})
+
+	t.Run("record", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "c.json")
+		cassette := recorded.Cassette()
+		if err := cassette.Save(path); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+		rep, err := NewReplayer(path)
+		if err != nil {
+			t.Fatalf("NewReplayer() error = %v", err)
+		}
+		real := &MockProvider{Response: "Madrid", StreamTokens: []string{"Ber", "lin"}}
+		rep.Mode = ReplayRecord
+		rep.Fallback = real
+
+		if resp, err := rep.Complete(ctx, NewRequest("Capital of Spain?")); err != nil || resp.Content != "Madrid" {
+			t.Errorf("Complete() = %+v, %v", resp, err)
+		}
+		content, errs, err := rep.Stream(ctx, NewRequest("Capital of Germany?"))
+		if err != nil {
+			t.Fatalf("Stream() error = %v", err)
+		}
+		if chunks, err := collect(content, errs); err != nil || strings.Join(chunks, "") != "Berlin" {
+			t.Errorf("collect() = %v, %v", chunks, err)
+		}
+
+		// The new exchanges are saved next to the old ones and replayed
+		// without calling the provider again
+		strict, err := NewReplayer(path)
+		if err != nil {
+			t.Fatalf("NewReplayer() error = %v", err)
+		}
+		for prompt, want := range map[string]string{
+			"Capital of France?":  "Paris",
+			"Capital of Spain?":   "Madrid",
+			"Capital of Germany?": "Berlin",
+		} {
+			if resp, err := strict.Complete(ctx, NewRequest(prompt)); err != nil || resp.Content != want {
+				t.Errorf("Complete(%q) = %+v, %v, want %q", prompt, resp, err, want)
+			}
+		}
+		if resp, _ := rep.Complete(ctx, NewRequest("Capital of Spain?")); resp.Content != "Madrid" || len(real.Requests) != 2 {
+			t.Errorf("recorded request should be replayed, got %q after %d provider calls", resp.Content, len(real.Requests))
+		}
+	})
+}
+
+func TestRecorderSkipsFailures(t *testing.T) {
+	ctx := context.Background()
+	rec := NewRecorder(&MockProvider{Errors: []error{ErrServer}}, filepath.Join(t.TempDir(), "c.json"))
+
+	if _, err := rec.Complete(ctx, NewRequest("Hi")); !errors.Is(err, ErrServer) {
+		t.Errorf("error = %v, want ErrServer", err)
+	}
+
+	rec2 := NewRecorder(partialStream{chunk: "Hel", err: ErrServer}, filepath.Join(t.TempDir(), "c.json"))
+	content, errs, _ := rec2.Stream(ctx, NewRequest("Hi"))
+	if _, err := collect(content, errs); !errors.Is(err, ErrServer) {
+		t.Errorf("stream error = %v, want ErrServer", err)
+	}
+
+	if n := len(rec.Cassette().Interactions) + len(rec2.Cassette().Interactions); n != 0 {
+		t.Errorf("failed exchanges should not be recorded, got %d", n)
+	}
+}
+
+func TestLoadCassette(t *testing.T) {
+	rep, err := NewReplayer(filepath.Join("testdata", "cassette.json"))
+	if err != nil {
+		t.Fatalf("NewReplayer() error = %v", err)
+	}
+
+	req := Request{Messages: []Message{
+		{Role: RoleSystem, Content: "You are a classifier."},
+		{Role: RoleUser, Content: "Classify: great product"},
+	}}
+	resp, err := rep.Complete(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if resp.Content != `{"label": "positive"}` || resp.Usage.TotalTokens != 24 {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+
+	if _, err := NewReplayer(filepath.Join("testdata", "missing.json")); err == nil {
+		t.Error("expected error for a missing cassette")
+	}
+}
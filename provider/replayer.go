@@ -0,0 +1,226 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ErrNoRecording is returned by a Replayer for a request it has no
+// recording of
+var ErrNoRecording = errors.New("no recording matches the request")
+
+// ReplayMode decides how a Replayer answers requests it has no recording of
+type ReplayMode int
+
+const (
+	// ReplayStrict fails unrecorded requests with ErrNoRecording
+	ReplayStrict ReplayMode = iota
+	// ReplayFallback passes unrecorded requests to the Replayer's Fallback
+	// provider, e.g. a MockProvider with a default response
+	ReplayFallback
+	// ReplayRecord passes unrecorded requests to the Fallback provider, which
+	// is usually the real one, and adds the new exchanges to the cassette so
+	// that it grows as tests change
+	ReplayRecord
+)
+
+// Replayer serves the exchanges in a cassette, matching requests by their
+// hash, so that tests of multi-step flows run without network access. A
+// request made several times is answered with its recordings in order, the
+// last one repeating once they run out. Mode decides what happens to
+// requests without a recording; by default they fail.
+type Replayer struct {
+	cassette Cassette
+	path     string // where recorded exchanges are saved, if anywhere
+
+	// Mode decides how requests without a recording are answered
+	Mode ReplayMode
+	// Fallback answers requests without a recording in the ReplayFallback
+	// and ReplayRecord modes, which require it. Strict replayers ignore it.
+	Fallback Provider
+
+	mu     sync.Mutex
+	served map[string]int // times each key has been served
+	misses []Request
+}
+
+// NewReplayer creates a replayer for the cassette at path. In ReplayRecord
+// mode new exchanges are saved back to the same file.
+func NewReplayer(path string) (*Replayer, error) {
+	c, err := LoadCassette(path)
+	if err != nil {
+		return nil, err
+	}
+	r := Replay(*c)
+	r.path = path
+	return r, nil
+}
+
+// Replay creates a replayer for a cassette. In ReplayRecord mode new
+// exchanges are only kept in memory, see Cassette.
+func Replay(c Cassette) *Replayer {
+	return &Replayer{cassette: c, served: make(map[string]int)}
+}
+
+// Name returns the name of the recorded provider
+func (r *Replayer) Name() string {
+	return r.cassette.Provider
+}
+
+// Capabilities returns the capabilities of the recorded provider
+func (r *Replayer) Capabilities() Capabilities {
+	return r.cassette.Capabilities
+}
+
+// Complete returns the recorded response for the request. Streamed
+// recordings are answered with their chunks joined together.
+func (r *Replayer) Complete(ctx context.Context, req Request) (Response, error) {
+	i, ok := r.match(req)
+	if ok {
+		return i.Response, nil
+	}
+	if err := r.checkFallback(req); err != nil {
+		return Response{}, err
+	}
+
+	resp, err := r.Fallback.Complete(ctx, req)
+	if err != nil || r.Mode != ReplayRecord {
+		return resp, err
+	}
+	if resp.Provider == "" {
+		resp.Provider = NameOf(r.Fallback)
+	}
+	if err := r.record(Interaction{Key: req.Hash(), Request: req, Response: resp}); err != nil {
+		return Response{}, err
+	}
+	return resp, nil
+}
+
+// Stream streams the recorded chunks for the request. Recordings made with
+// Complete are streamed as a single chunk.
+func (r *Replayer) Stream(ctx context.Context, req Request) (<-chan string, <-chan error, error) {
+	i, ok := r.match(req)
+	if !ok {
+		return r.streamFallback(ctx, req)
+	}
+
+	chunks := i.Chunks
+	if chunks == nil {
+		chunks = []string{i.Response.Content}
+	}
+
+	content := make(chan string)
+	errs := make(chan error, 1)
+	go func() {
+		defer close(content)
+		defer close(errs)
+
+		for _, chunk := range chunks {
+			select {
+			case content <- chunk:
+			case <-ctx.Done():
+				errs <- ctx.Err()
+				return
+			}
+		}
+	}()
+
+	return content, errs, nil
+}
+
+// streamFallback streams a request without a recording from the Fallback
+// provider, recording it once it ends in ReplayRecord mode
+func (r *Replayer) streamFallback(ctx context.Context, req Request) (<-chan string, <-chan error, error) {
+	if err := r.checkFallback(req); err != nil {
+		return nil, nil, err
+	}
+
+	content, errs, err := r.Fallback.Stream(ctx, req)
+	if err != nil || r.Mode != ReplayRecord {
+		return content, errs, err
+	}
+
+	content, errs = captureStream(ctx, content, errs, func(chunks []string) error {
+		resp := Response{Content: strings.Join(chunks, ""), Provider: NameOf(r.Fallback)}
+		return r.record(Interaction{Key: req.Hash(), Request: req, Response: resp, Chunks: chunks})
+	})
+	return content, errs, nil
+}
+
+// checkFallback reports why a request without a recording cannot be passed
+// to the Fallback provider, if it cannot
+func (r *Replayer) checkFallback(req Request) error {
+	switch {
+	case r.Mode == ReplayStrict:
+		return r.missError(req)
+	case r.Fallback == nil:
+		return fmt.Errorf("%w: replayer has no fallback provider", r.missError(req))
+	}
+	return nil
+}
+
+// Cassette returns a copy of the cassette, including any exchanges recorded
+// in ReplayRecord mode
+func (r *Replayer) Cassette() Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c := r.cassette
+	c.Interactions = append([]Interaction(nil), c.Interactions...)
+	return c
+}
+
+// record adds an interaction to the cassette, saving it if it came from a file
+func (r *Replayer) record(i Interaction) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cassette.Interactions = append(r.cassette.Interactions, i)
+	if r.path == "" {
+		return nil
+	}
+	return r.cassette.Save(r.path)
+}
+
+// Misses returns the requests that had no recording
+func (r *Replayer) Misses() []Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Request(nil), r.misses...)
+}
+
+// match finds the next recording for req
+func (r *Replayer) match(req Request) (Interaction, bool) {
+	key := req.Hash()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var matches []Interaction
+	for _, i := range r.cassette.Interactions {
+		if i.Key == key {
+			matches = append(matches, i)
+		}
+	}
+	if len(matches) == 0 {
+		r.misses = append(r.misses, req)
+		return Interaction{}, false
+	}
+
+	n := r.served[key]
+	r.served[key]++
+	return matches[min(n, len(matches)-1)], true
+}
+
+// missError describes a request with no recording
+func (r *Replayer) missError(req Request) error {
+	prompt := req.Prompt()
+	if len(prompt) > 200 {
+		prompt = prompt[:200] + "..."
+	}
+	prompt = strings.ReplaceAll(prompt, "\n", " ")
+	return fmt.Errorf("%w: %s (prompt %q)", ErrNoRecording, req.Hash(), prompt)
+}
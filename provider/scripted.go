@@ -0,0 +1,274 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// ErrNoRule is returned by a ScriptedProvider for a request that matches
+// none of its rules
+var ErrNoRule = errors.New("no rule matches the request")
+
+// Matcher decides whether a rule applies to a request
+type Matcher func(req Request) bool
+
+// Contains matches requests whose prompt contains substr
+func Contains(substr string) Matcher {
+	return func(req Request) bool {
+		return strings.Contains(req.Prompt(), substr)
+	}
+}
+
+// MatchRegexp matches requests whose prompt matches the regular expression
+// pattern. It panics if pattern does not compile.
+func MatchRegexp(pattern string) Matcher {
+	re := regexp.MustCompile(pattern)
+	return func(req Request) bool {
+		return re.MatchString(req.Prompt())
+	}
+}
+
+// TestingT is the part of *testing.T used by AssertExpectations
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...any)
+}
+
+// ScriptedProvider is a mock provider that answers each request according to
+// the first rule matching it, for testing flows that send different prompts:
+//
+//	p := provider.NewScripted()
+//	p.On(provider.Contains("billing")).Respond(`{"route": "billing"}`).Once()
+//	p.On(provider.MatchRegexp(`(?i)refund`)).Respond(`{"route": "refunds"}`)
+//	...
+//	p.AssertExpectations(t)
+type ScriptedProvider struct {
+	Supports Capabilities // Capabilities reported to the generator
+
+	mu        sync.Mutex
+	rules     []*Rule
+	unmatched []Request
+}
+
+// NewScripted creates a scripted provider with no rules
+func NewScripted() *ScriptedProvider {
+	return &ScriptedProvider{}
+}
+
+// On adds a rule for requests accepted by match. Rules are tried in the order
+// they were added.
+func (s *ScriptedProvider) On(match Matcher) *Rule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r := &Rule{match: match, provider: s}
+	s.rules = append(s.rules, r)
+	return r
+}
+
+// Complete answers the request with the next step of the first matching rule
+func (s *ScriptedProvider) Complete(_ context.Context, req Request) (Response, error) {
+	st, err := s.next(req)
+	if err != nil {
+		return Response{}, err
+	}
+	if st.err != nil {
+		return Response{}, st.err
+	}
+	if st.chunks != nil {
+		return Response{Content: strings.Join(st.chunks, ""), FinishReason: "stop"}, nil
+	}
+	return st.response, nil
+}
+
+// Stream streams the next step of the first matching rule. Steps set with
+// Respond are streamed as a single chunk.
+func (s *ScriptedProvider) Stream(ctx context.Context, req Request) (<-chan string, <-chan error, error) {
+	st, err := s.next(req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content := make(chan string)
+	errs := make(chan error, 1)
+
+	if st.err != nil {
+		close(content)
+		errs <- st.err
+		close(errs)
+		return content, errs, nil
+	}
+
+	chunks := st.chunks
+	if chunks == nil {
+		chunks = []string{st.response.Content}
+	}
+	go func() {
+		defer close(content)
+		defer close(errs)
+
+		for _, chunk := range chunks {
+			select {
+			case content <- chunk:
+			case <-ctx.Done():
+				errs <- ctx.Err()
+				return
+			}
+		}
+	}()
+
+	return content, errs, nil
+}
+
+// Name returns "mock"
+func (s *ScriptedProvider) Name() string {
+	return "mock"
+}
+
+// Capabilities reports the configured capabilities
+func (s *ScriptedProvider) Capabilities() Capabilities {
+	return s.Supports
+}
+
+// Unmatched returns the requests that matched no rule
+func (s *ScriptedProvider) Unmatched() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.unmatched...)
+}
+
+// AssertExpectations reports every request that matched no rule and every
+// rule that was called a different number of times than expected. Rules
+// without Times must be called at least once. It returns true if all
+// expectations were met.
+func (s *ScriptedProvider) AssertExpectations(t TestingT) bool {
+	t.Helper()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ok := true
+	for _, req := range s.unmatched {
+		t.Errorf("unexpected request matching no rule: %q", req.Prompt())
+		ok = false
+	}
+	for i, r := range s.rules {
+		switch {
+		case r.times > 0 && r.calls != r.times:
+			t.Errorf("rule %d: expected %d calls, got %d", i+1, r.times, r.calls)
+			ok = false
+		case r.times == 0 && r.calls == 0:
+			t.Errorf("rule %d: never called", i+1)
+			ok = false
+		}
+	}
+	return ok
+}
+
+// next finds the rule for req and advances it
+func (s *ScriptedProvider) next(req Request) (step, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, r := range s.rules {
+		if r.times > 0 && r.calls >= r.times {
+			continue
+		}
+		if !r.match(req) {
+			continue
+		}
+		if len(r.steps) == 0 {
+			return step{}, fmt.Errorf("rule %d has no response", i+1)
+		}
+
+		st := r.steps[min(r.calls, len(r.steps)-1)]
+		r.calls++
+		r.requests = append(r.requests, req)
+		return st, nil
+	}
+
+	s.unmatched = append(s.unmatched, req)
+	return step{}, fmt.Errorf("%w: %q", ErrNoRule, req.Prompt())
+}
+
+// Rule is a ScriptedProvider rule. Each call takes the rule's next step, the
+// last one repeating once they run out.
+type Rule struct {
+	match    Matcher
+	provider *ScriptedProvider
+	steps    []step
+	times    int
+	calls    int
+	requests []Request
+}
+
+// step is a single scripted answer
+type step struct {
+	response Response
+	chunks   []string
+	err      error
+}
+
+// Respond adds a step answering with content
+func (r *Rule) Respond(content string) *Rule {
+	return r.RespondWith(Response{Content: content, FinishReason: "stop"})
+}
+
+// RespondWith adds a step answering with resp, e.g. to include usage or
+// tool calls
+func (r *Rule) RespondWith(resp Response) *Rule {
+	return r.add(step{response: resp})
+}
+
+// Stream adds a step streaming chunks
+func (r *Rule) Stream(chunks ...string) *Rule {
+	if chunks == nil {
+		chunks = []string{}
+	}
+	return r.add(step{chunks: chunks})
+}
+
+// Fail adds a step failing with err
+func (r *Rule) Fail(err error) *Rule {
+	return r.add(step{err: err})
+}
+
+// Times limits the rule to n calls, after which requests fall through to
+// later rules, and makes AssertExpectations check that it was called exactly
+// n times
+func (r *Rule) Times(n int) *Rule {
+	r.provider.mu.Lock()
+	defer r.provider.mu.Unlock()
+	r.times = n
+	return r
+}
+
+// Once is shorthand for Times(1)
+func (r *Rule) Once() *Rule {
+	return r.Times(1)
+}
+
+// Calls returns the number of requests the rule has answered
+func (r *Rule) Calls() int {
+	r.provider.mu.Lock()
+	defer r.provider.mu.Unlock()
+	return r.calls
+}
+
+// Requests returns the requests the rule has answered
+func (r *Rule) Requests() []Request {
+	r.provider.mu.Lock()
+	defer r.provider.mu.Unlock()
+	return append([]Request(nil), r.requests...)
+}
+
+func (r *Rule) add(st step) *Rule {
+	r.provider.mu.Lock()
+	defer r.provider.mu.Unlock()
+	r.steps = append(r.steps, st)
+	return r
+}
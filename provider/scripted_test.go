@@ -0,0 +1,156 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// fakeT records the errors reported by AssertExpectations
+type fakeT struct {
+	errors []string
+}
+
+func (f *fakeT) Helper() {}
+
+func (f *fakeT) Errorf(format string, args ...any) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func TestScripted(t *testing.T) {
+	ctx := context.Background()
+	p := NewScripted()
+	billing := p.On(Contains("invoice")).Respond(`{"route": "billing"}`)
+	refunds := p.On(MatchRegexp(`(?i)refund`)).Respond("first").Respond("second")
+	p.On(func(req Request) bool { return len(req.Messages) > 1 }).Respond("multi")
+
+	tests := []struct {
+		req  Request
+		want string
+	}{
+		{NewRequest("Where is my invoice?"), `{"route": "billing"}`},
+		{NewRequest("I want a REFUND"), "first"},
+		{NewRequest("refund please"), "second"},
+		{NewRequest("refund again"), "second"},
+		{Request{Messages: []Message{{Role: RoleSystem, Content: "Route"}, {Role: RoleUser, Content: "Hi"}}}, "multi"},
+	}
+	for _, tt := range tests {
+		resp, err := p.Complete(ctx, tt.req)
+		if err != nil || resp.Content != tt.want {
+			t.Errorf("Complete(%q) = %q, %v, want %q", tt.req.Prompt(), resp.Content, err, tt.want)
+		}
+	}
+
+	if billing.Calls() != 1 || refunds.Calls() != 3 {
+		t.Errorf("Calls() = %d, %d, want 1, 3", billing.Calls(), refunds.Calls())
+	}
+	if got := refunds.Requests()[0].Prompt(); got != "I want a REFUND" {
+		t.Errorf("Requests()[0] = %q", got)
+	}
+	if !p.AssertExpectations(t) {
+		t.Error("expected all expectations to be met")
+	}
+}
+
+func TestScriptedSteps(t *testing.T) {
+	ctx := context.Background()
+	p := NewScripted()
+	p.On(Contains("story")).
+		Fail(ErrRateLimit).
+		Stream("Once", " upon", " a time").
+		RespondWith(Response{Content: "The end", Usage: Usage{TotalTokens: 7}})
+
+	if _, err := p.Complete(ctx, NewRequest("Tell a story")); !errors.Is(err, ErrRateLimit) {
+		t.Errorf("first call error = %v, want ErrRateLimit", err)
+	}
+
+	content, errs, err := p.Stream(ctx, NewRequest("Tell a story"))
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+	chunks, err := collect(content, errs)
+	if err != nil || strings.Join(chunks, "|") != "Once| upon| a time" {
+		t.Errorf("streamed %v, %v", chunks, err)
+	}
+
+	resp, err := p.Complete(ctx, NewRequest("Tell a story"))
+	if err != nil || resp.Content != "The end" || resp.Usage.TotalTokens != 7 {
+		t.Errorf("Complete() = %+v, %v", resp, err)
+	}
+
+	// Responses are streamed as one chunk and stream failures arrive on errs
+	content, errs, _ = p.Stream(ctx, NewRequest("Tell a story"))
+	if chunks, _ := collect(content, errs); len(chunks) != 1 || chunks[0] != "The end" {
+		t.Errorf("streamed %v", chunks)
+	}
+
+	q := NewScripted()
+	q.On(Contains("Hi")).Fail(ErrServer)
+	content, errs, _ = q.Stream(ctx, NewRequest("Hi"))
+	if _, err := collect(content, errs); !errors.Is(err, ErrServer) {
+		t.Errorf("stream error = %v, want ErrServer", err)
+	}
+}
+
+func TestScriptedTimes(t *testing.T) {
+	ctx := context.Background()
+	p := NewScripted()
+	p.On(Contains("classify")).Respond("positive").Times(2)
+	p.On(Contains("classify")).Respond("fallback")
+
+	var got []string
+	for i := 0; i < 3; i++ {
+		resp, _ := p.Complete(ctx, NewRequest("classify this"))
+		got = append(got, resp.Content)
+	}
+	if strings.Join(got, ",") != "positive,positive,fallback" {
+		t.Errorf("responses = %v", got)
+	}
+	if !p.AssertExpectations(t) {
+		t.Error("expected all expectations to be met")
+	}
+}
+
+func TestScriptedAssertExpectations(t *testing.T) {
+	ctx := context.Background()
+	p := NewScripted()
+	p.On(Contains("billing")).Respond("billing").Once()
+	p.On(Contains("refund")).Respond("refunds").Times(2)
+	p.On(Contains("shipping")).Respond("shipping")
+
+	p.Complete(ctx, NewRequest("refund"))
+	if _, err := p.Complete(ctx, NewRequest("hello")); !errors.Is(err, ErrNoRule) {
+		t.Errorf("error = %v, want ErrNoRule", err)
+	}
+	if _, _, err := p.Stream(ctx, NewRequest("hello again")); !errors.Is(err, ErrNoRule) {
+		t.Errorf("Stream() error = %v, want ErrNoRule", err)
+	}
+
+	ft := &fakeT{}
+	if p.AssertExpectations(ft) {
+		t.Error("expected unmet expectations")
+	}
+	want := []string{
+		`unexpected request matching no rule: "hello"`,
+		`unexpected request matching no rule: "hello again"`,
+		"rule 1: expected 1 calls, got 0",
+		"rule 2: expected 2 calls, got 1",
+		"rule 3: never called",
+	}
+	if strings.Join(ft.errors, "\n") != strings.Join(want, "\n") {
+		t.Errorf("reported:\n%s\nwant:\n%s", strings.Join(ft.errors, "\n"), strings.Join(want, "\n"))
+	}
+	if len(p.Unmatched()) != 2 {
+		t.Errorf("Unmatched() = %d requests, want 2", len(p.Unmatched()))
+	}
+}
+
+func TestScriptedRuleWithoutResponse(t *testing.T) {
+	p := NewScripted()
+	p.On(Contains("Hi"))
+	if _, err := p.Complete(context.Background(), NewRequest("Hi")); err == nil {
+		t.Error("expected error for a rule without a response")
+	}
+}
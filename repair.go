@@ -0,0 +1,41 @@
+package promptgen
+
+import (
+	"fmt"
+
+	"github.com/arjunsriva/promptgen/provider"
+)
+
+// RepairAttempt records a response that failed parsing or validation.
+// When repair is enabled, the attempts are available under the "attempts"
+// key of Error.Details.
+type RepairAttempt struct {
+	Attempt  int    `json:"attempt"`
+	Response string `json:"response"`
+	Error    string `json:"error"`
+}
+
+// WithRepair enables self-repair. When a response fails parsing or validation,
+// the model is shown its previous response along with the errors and asked to
+// correct it, up to maxAttempts times.
+func (g *Generator[I, O]) WithRepair(maxAttempts int) *Generator[I, O] {
+	g.repairAttempts = maxAttempts
+	return g
+}
+
+// repairRequest extends the original request with the rejected response and
+// a follow-up message asking the model to correct it
+func repairRequest(req provider.Request, response, problem string) provider.Request {
+	messages := make([]provider.Message, 0, len(req.Messages)+2)
+	messages = append(messages, req.Messages...)
+	messages = append(messages,
+		provider.Message{Role: provider.RoleAssistant, Content: response},
+		provider.Message{Role: provider.RoleUser, Content: fmt.Sprintf(`Your previous response was rejected with the following errors:
+%s
+
+Respond again with a corrected answer that fixes these errors.`, problem)},
+	)
+
+	req.Messages = messages
+	return req
+}
@@ -0,0 +1,147 @@
+package promptgen
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/arjunsriva/promptgen/provider"
+)
+
+// sequenceProvider returns its responses in order, repeating the last one
+type sequenceProvider struct {
+	responses []string
+	prompts   []string
+	requests  []provider.Request
+}
+
+func (p *sequenceProvider) Complete(_ context.Context, req provider.Request) (provider.Response, error) {
+	p.prompts = append(p.prompts, req.Prompt())
+	p.requests = append(p.requests, req)
+	i := len(p.prompts) - 1
+	if i >= len(p.responses) {
+		i = len(p.responses) - 1
+	}
+	return provider.Response{
+		Content: p.responses[i],
+		Model:   "gpt-4o",
+		Usage:   provider.Usage{PromptTokens: 100, CompletionTokens: 10, TotalTokens: 110},
+	}, nil
+}
+
+func (p *sequenceProvider) Stream(_ context.Context, _ provider.Request) (<-chan string, <-chan error, error) {
+	return nil, nil, errors.New("not implemented")
+}
+
+type repairTestOutput struct {
+	Response string `json:"response" jsonschema:"required,maxLength=5"`
+}
+
+func TestRepair(t *testing.T) {
+	t.Run("repairs validation failure", func(t *testing.T) {
+		mock := &sequenceProvider{responses: []string{
+			`{"response": "far too long"}`,
+			`{"response": "short"}`,
+		}}
+
+		gen, _ := Create[TestInput, repairTestOutput]("Hello {{.Message}}")
+		gen.WithProvider(mock).WithRepair(2)
+
+		result, err := gen.Run(context.Background(), TestInput{Message: "test"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Response != "short" {
+			t.Errorf("expected 'short', got %q", result.Response)
+		}
+		if len(mock.prompts) != 2 {
+			t.Fatalf("expected 2 prompts, got %d", len(mock.prompts))
+		}
+
+		repair := mock.prompts[1]
+		if !strings.HasPrefix(repair, mock.prompts[0]) {
+			t.Error("repair prompt should include the original prompt")
+		}
+		if !strings.Contains(repair, `{"response": "far too long"}`) {
+			t.Error("repair prompt should include the bad response")
+		}
+		if !strings.Contains(repair, "String length must be less than or equal to 5") {
+			t.Errorf("repair prompt should include the validation errors, got:\n%s", repair)
+		}
+
+		messages := mock.requests[1].Messages
+		if len(messages) != 3 || messages[1].Role != provider.RoleAssistant || messages[2].Role != provider.RoleUser {
+			t.Errorf("repair should replay the bad response as an assistant message, got %+v", messages)
+		}
+	})
+
+	t.Run("repairs parse failure", func(t *testing.T) {
+		mock := &sequenceProvider{responses: []string{
+			`not json`,
+			`{"response": "ok"}`,
+		}}
+
+		gen, _ := Create[TestInput, repairTestOutput]("Hello {{.Message}}")
+		gen.WithProvider(mock).WithRepair(1)
+
+		result, err := gen.Run(context.Background(), TestInput{Message: "test"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Response != "ok" {
+			t.Errorf("expected 'ok', got %q", result.Response)
+		}
+	})
+
+	t.Run("records attempts when exhausted", func(t *testing.T) {
+		mock := &sequenceProvider{responses: []string{
+			`not json`,
+			`{"response": "still too long"}`,
+		}}
+
+		gen, _ := Create[TestInput, repairTestOutput]("Hello {{.Message}}")
+		gen.WithProvider(mock).WithRepair(2)
+
+		_, err := gen.Run(context.Background(), TestInput{Message: "test"})
+		if !IsValidation(err) {
+			t.Fatalf("expected validation error, got %v", err)
+		}
+		if len(mock.prompts) != 3 {
+			t.Errorf("expected 3 prompts, got %d", len(mock.prompts))
+		}
+
+		var perr *Error
+		if !errors.As(err, &perr) {
+			t.Fatalf("expected *Error, got %T", err)
+		}
+		attempts, ok := perr.Details["attempts"].([]RepairAttempt)
+		if !ok {
+			t.Fatalf("expected attempts in details, got %v", perr.Details)
+		}
+		if len(attempts) != 3 {
+			t.Fatalf("expected 3 attempts, got %d", len(attempts))
+		}
+		if attempts[0].Response != "not json" || !strings.Contains(attempts[0].Error, "failed to parse response") {
+			t.Errorf("unexpected first attempt: %+v", attempts[0])
+		}
+		if attempts[2].Attempt != 3 || attempts[2].Response != `{"response": "still too long"}` {
+			t.Errorf("unexpected last attempt: %+v", attempts[2])
+		}
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		mock := &sequenceProvider{responses: []string{`not json`}}
+
+		gen, _ := Create[TestInput, repairTestOutput]("Hello {{.Message}}")
+		gen.WithProvider(mock)
+
+		_, err := gen.Run(context.Background(), TestInput{Message: "test"})
+		if !errors.Is(err, ErrInvalidResponse) {
+			t.Errorf("expected invalid response error, got %v", err)
+		}
+		if len(mock.prompts) != 1 {
+			t.Errorf("expected 1 prompt, got %d", len(mock.prompts))
+		}
+	})
+}
@@ -0,0 +1,60 @@
+package promptgen
+
+import (
+	"context"
+	"time"
+
+	"github.com/arjunsriva/promptgen/provider"
+)
+
+// Result is the validated output of a call together with metadata about how
+// it was produced. Usage and Cost cover every request made during the call,
+// including retries and repair attempts.
+type Result[O any] struct {
+	Output       O
+	Usage        provider.Usage
+	Model        string
+	FinishReason string
+	Latency      time.Duration
+	// Provider is the name of the provider that produced the output, which
+	// may differ between calls when using provider.Fallback
+	Provider string
+	// Cost is the estimated cost in USD, or zero when the model is not in
+	// the generator's pricing table
+	Cost float64
+	// CacheHit reports whether the output came from the generator's cache,
+	// in which case no tokens were used
+	CacheHit bool
+}
+
+// RunWithMeta executes the prompt like Run and also reports token usage,
+// model, latency, finish reason and estimated cost. The result is returned
+// even on error so that usage of failed calls can still be accounted for.
+func (g *Generator[I, O]) RunWithMeta(ctx context.Context, input I) (*Result[O], error) {
+	return g.run(ctx, input)
+}
+
+// WithPricing sets the pricing table used to estimate costs
+func (g *Generator[I, O]) WithPricing(pricing provider.Pricing) *Generator[I, O] {
+	g.pricing = pricing
+	return g
+}
+
+// pricingTable returns the configured pricing, defaulting to provider.DefaultPricing
+func (g *Generator[I, O]) pricingTable() provider.Pricing {
+	if g.pricing != nil {
+		return g.pricing
+	}
+	return provider.DefaultPricing
+}
+
+// record adds a provider response to the result's totals
+func (r *Result[O]) record(resp provider.Response, pricing provider.Pricing) {
+	r.Usage = r.Usage.Add(resp.Usage)
+	r.Model = resp.Model
+	r.Provider = resp.Provider
+	r.FinishReason = resp.FinishReason
+	if cost, ok := pricing.Cost(resp.Model, resp.Usage); ok {
+		r.Cost += cost
+	}
+}
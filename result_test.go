@@ -0,0 +1,90 @@
+package promptgen
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/arjunsriva/promptgen/provider"
+)
+
+// namedProvider is a mock provider with its own name
+type namedProvider struct {
+	*provider.MockProvider
+	name string
+}
+
+func (n namedProvider) Name() string {
+	return n.name
+}
+
+func TestRunWithMeta(t *testing.T) {
+	t.Run("reports usage and cost", func(t *testing.T) {
+		mock := &provider.MockProvider{
+			Response: `{"response": "Hello"}`,
+			Usage:    provider.Usage{PromptTokens: 1000, CompletionTokens: 500, TotalTokens: 1500},
+		}
+
+		gen, _ := Create[TestInput, TestOutput]("Hello {{.Message}}")
+		gen.WithProvider(mock).
+			WithModel("my-model").
+			WithPricing(provider.Pricing{"my-model": {Input: 1, Output: 2}})
+
+		result, err := gen.RunWithMeta(context.Background(), TestInput{Message: "test"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Output.Response != "Hello" {
+			t.Errorf("output = %q, want 'Hello'", result.Output.Response)
+		}
+		if result.Usage != mock.Usage {
+			t.Errorf("usage = %+v, want %+v", result.Usage, mock.Usage)
+		}
+		if result.Model != "my-model" || result.FinishReason != "stop" {
+			t.Errorf("unexpected model or finish reason: %q %q", result.Model, result.FinishReason)
+		}
+		if want := 0.002; math.Abs(result.Cost-want) > 1e-9 {
+			t.Errorf("cost = %v, want %v", result.Cost, want)
+		}
+		if result.Latency <= 0 {
+			t.Error("expected latency to be recorded")
+		}
+		if result.Provider != "mock" {
+			t.Errorf("provider = %q, want mock", result.Provider)
+		}
+	})
+
+	t.Run("reports the provider that answered", func(t *testing.T) {
+		primary := &provider.MockProvider{Errors: []error{provider.ErrRateLimit}}
+		backup := namedProvider{&provider.MockProvider{Response: `{"response": "Hello"}`}, "backup"}
+
+		gen, _ := Create[TestInput, TestOutput]("Hello {{.Message}}")
+		gen.WithProvider(provider.Fallback(primary, backup))
+
+		result, err := gen.RunWithMeta(context.Background(), TestInput{Message: "test"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Provider != "backup" {
+			t.Errorf("provider = %q, want backup", result.Provider)
+		}
+	})
+
+	t.Run("sums usage across repair attempts", func(t *testing.T) {
+		mock := &sequenceProvider{responses: []string{`not json`, `not json`}}
+
+		gen, _ := Create[TestInput, TestOutput]("Hello {{.Message}}")
+		gen.WithProvider(mock).WithRepair(1)
+
+		result, err := gen.RunWithMeta(context.Background(), TestInput{Message: "test"})
+		if err == nil {
+			t.Fatal("expected error")
+		}
+		if result.Usage.TotalTokens != 220 {
+			t.Errorf("total tokens = %d, want 220", result.Usage.TotalTokens)
+		}
+		if result.Cost == 0 {
+			t.Error("expected cost from default pricing")
+		}
+	})
+}
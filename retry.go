@@ -0,0 +1,100 @@
+package promptgen
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"github.com/arjunsriva/promptgen/provider"
+)
+
+// RetryPolicy controls how Run retries transient provider errors
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry, doubled on each attempt
+	BaseDelay time.Duration
+	// MaxDelay caps the computed backoff delay. It does not cap Retry-After
+	// hints from the provider, since retrying sooner would only be rejected
+	// again; use a context deadline to bound the total wait.
+	MaxDelay time.Duration
+	// Jitter randomly shortens each delay by up to this fraction (0-1)
+	Jitter float64
+	// RetryOn lists the error classes to retry, e.g. ErrRateLimit or ErrTimeout.
+	// Defaults to ErrRateLimit when empty.
+	RetryOn []error
+	// After waits for the given delay, like time.After, which it defaults to.
+	// Set it to a fake clock to test retries without real delays.
+	After func(time.Duration) <-chan time.Time
+}
+
+// DefaultRetryPolicy returns a policy suitable for most providers
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   500 * time.Millisecond,
+		MaxDelay:    30 * time.Second,
+		Jitter:      0.2,
+		RetryOn:     []error{ErrRateLimit, ErrTimeout},
+	}
+}
+
+// WithRetry sets the retry policy for transient provider errors
+func (g *Generator[I, O]) WithRetry(policy RetryPolicy) *Generator[I, O] {
+	g.retry = policy
+	return g
+}
+
+// shouldRetry reports whether err belongs to one of the retryable classes
+func (p RetryPolicy) shouldRetry(err error) bool {
+	retryOn := p.RetryOn
+	if len(retryOn) == 0 {
+		retryOn = []error{ErrRateLimit}
+	}
+	for _, target := range retryOn {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// delay returns how long to wait before the given retry (1-based).
+// A Retry-After hint from the provider takes precedence over backoff.
+func (p RetryPolicy) delay(retry int, err error) time.Duration {
+	if after, ok := provider.RetryAfter(err); ok {
+		return after
+	}
+	if p.BaseDelay <= 0 {
+		return 0
+	}
+
+	d := p.BaseDelay << (retry - 1)
+	if d>>(retry-1) != p.BaseDelay {
+		// The shift overflowed, so the backoff is as long as it gets
+		d = math.MaxInt64
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	if p.Jitter > 0 {
+		d -= time.Duration(p.Jitter * rand.Float64() * float64(d))
+	}
+	return d
+}
+
+// sleep waits for d or until the context is done
+func (p RetryPolicy) sleep(ctx context.Context, d time.Duration) error {
+	after := p.After
+	if after == nil {
+		after = time.After
+	}
+	select {
+	case <-after(d):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
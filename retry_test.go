@@ -0,0 +1,218 @@
+package promptgen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/arjunsriva/promptgen/provider"
+)
+
+// fakeClock fires immediately and records the requested delays
+type fakeClock struct {
+	delays []time.Duration
+	block  bool
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.delays = append(c.delays, d)
+	ch := make(chan time.Time, 1)
+	if !c.block {
+		ch <- time.Time{}
+	}
+	return ch
+}
+
+// withClock returns policy with its waits going through c
+func withClock(policy RetryPolicy, c *fakeClock) RetryPolicy {
+	policy.After = c.After
+	return policy
+}
+
+func TestRetryPolicy(t *testing.T) {
+	policy := RetryPolicy{
+		MaxAttempts: 4,
+		BaseDelay:   100 * time.Millisecond,
+		MaxDelay:    250 * time.Millisecond,
+	}
+
+	t.Run("retries rate limits with backoff", func(t *testing.T) {
+		mock := &provider.MockProvider{
+			Response: `{"response": "Hello"}`,
+			Errors:   []error{provider.ErrRateLimit, provider.ErrRateLimit, provider.ErrRateLimit},
+		}
+		clk := &fakeClock{}
+
+		gen, _ := Create[TestInput, TestOutput]("Hello {{.Message}}")
+		gen.WithProvider(mock).WithRetry(withClock(policy, clk))
+
+		result, err := gen.Run(context.Background(), TestInput{Message: "test"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Response != "Hello" {
+			t.Errorf("expected 'Hello', got %q", result.Response)
+		}
+
+		want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 250 * time.Millisecond}
+		if fmt.Sprint(clk.delays) != fmt.Sprint(want) {
+			t.Errorf("delays = %v, want %v", clk.delays, want)
+		}
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		mock := &provider.MockProvider{
+			Response: `{"response": "Hello"}`,
+			Errors:   []error{provider.ErrRateLimit, provider.ErrRateLimit, provider.ErrRateLimit, provider.ErrRateLimit},
+		}
+		clk := &fakeClock{}
+
+		gen, _ := Create[TestInput, TestOutput]("Hello {{.Message}}")
+		gen.WithProvider(mock).WithRetry(withClock(policy, clk))
+
+		_, err := gen.Run(context.Background(), TestInput{Message: "test"})
+		if !errors.Is(err, ErrRateLimit) {
+			t.Errorf("expected rate limit error, got %v", err)
+		}
+		if len(mock.Prompts) != 4 {
+			t.Errorf("expected 4 attempts, got %d", len(mock.Prompts))
+		}
+	})
+
+	t.Run("does not retry other errors", func(t *testing.T) {
+		mock := &provider.MockProvider{
+			Response: `{"response": "Hello"}`,
+			Errors:   []error{provider.ErrContextLength},
+		}
+
+		gen, _ := Create[TestInput, TestOutput]("Hello {{.Message}}")
+		gen.WithProvider(mock).WithRetry(withClock(policy, &fakeClock{}))
+
+		_, err := gen.Run(context.Background(), TestInput{Message: "test"})
+		if !errors.Is(err, ErrContextLength) {
+			t.Errorf("expected context length error, got %v", err)
+		}
+		if len(mock.Prompts) != 1 {
+			t.Errorf("expected 1 attempt, got %d", len(mock.Prompts))
+		}
+	})
+
+	t.Run("respects retry after hint", func(t *testing.T) {
+		mock := &provider.MockProvider{
+			Response: `{"response": "Hello"}`,
+			Errors: []error{&provider.RetryAfterError{
+				Err:   provider.ErrRateLimit,
+				After: 7 * time.Second,
+			}},
+		}
+		clk := &fakeClock{}
+
+		gen, _ := Create[TestInput, TestOutput]("Hello {{.Message}}")
+		gen.WithProvider(mock).WithRetry(withClock(policy, clk))
+
+		if _, err := gen.Run(context.Background(), TestInput{Message: "test"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(clk.delays) != 1 || clk.delays[0] != 7*time.Second {
+			t.Errorf("delays = %v, want [7s]", clk.delays)
+		}
+	})
+
+	t.Run("honours retry after from openai", func(t *testing.T) {
+		var calls int
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.Header().Set("Content-Type", "application/json")
+			if calls == 1 {
+				w.Header().Set("Retry-After", "7")
+				w.WriteHeader(http.StatusTooManyRequests)
+				w.Write([]byte(`{"error": {"message": "Rate limit reached", "type": "requests"}}`))
+				return
+			}
+			w.Write([]byte(`{"model": "gpt-4o", "choices": [{"message": {"role": "assistant", "content": "{\"response\": \"Hello\"}"}, "finish_reason": "stop"}]}`))
+		}))
+		defer server.Close()
+
+		p, err := provider.NewOpenAI(provider.OpenAIConfig{APIKey: "test-key", Model: "gpt-4o", BaseURL: server.URL})
+		if err != nil {
+			t.Fatalf("NewOpenAI() error = %v", err)
+		}
+		clk := &fakeClock{}
+
+		gen, _ := Create[TestInput, TestOutput]("Hello {{.Message}}")
+		gen.WithProvider(p).WithRetry(withClock(policy, clk))
+
+		if _, err := gen.Run(context.Background(), TestInput{Message: "test"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(clk.delays) != 1 || clk.delays[0] != 7*time.Second {
+			t.Errorf("delays = %v, want [7s]", clk.delays)
+		}
+	})
+
+	t.Run("stops when context is cancelled", func(t *testing.T) {
+		mock := &provider.MockProvider{
+			Response: `{"response": "Hello"}`,
+			Errors:   []error{provider.ErrRateLimit},
+		}
+
+		gen, _ := Create[TestInput, TestOutput]("Hello {{.Message}}")
+		gen.WithProvider(mock).WithRetry(withClock(policy, &fakeClock{block: true}))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			cancel()
+		}()
+
+		_, err := gen.Run(ctx, TestInput{Message: "test"})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected cancellation error, got %v", err)
+		}
+		if len(mock.Prompts) != 1 {
+			t.Errorf("expected 1 attempt, got %d", len(mock.Prompts))
+		}
+	})
+
+	t.Run("zero base delay", func(t *testing.T) {
+		p := RetryPolicy{MaxAttempts: 2, MaxDelay: 2 * time.Second}
+		if d := p.delay(1, provider.ErrRateLimit); d != 0 {
+			t.Errorf("delay = %v, want no backoff", d)
+		}
+	})
+
+	t.Run("overflow is capped", func(t *testing.T) {
+		p := RetryPolicy{BaseDelay: time.Second, MaxDelay: time.Minute}
+		for _, retry := range []int{40, 64, 100} {
+			if d := p.delay(retry, provider.ErrRateLimit); d != time.Minute {
+				t.Errorf("delay(%d) = %v, want 1m", retry, d)
+			}
+		}
+		p.MaxDelay = 0
+		if d := p.delay(64, provider.ErrRateLimit); d <= 0 {
+			t.Errorf("delay without a cap = %v, want a positive delay", d)
+		}
+	})
+
+	t.Run("retry after hint is not capped", func(t *testing.T) {
+		p := RetryPolicy{BaseDelay: time.Second, MaxDelay: time.Second}
+		err := &provider.RetryAfterError{Err: provider.ErrRateLimit, After: time.Minute}
+		if d := p.delay(1, err); d != time.Minute {
+			t.Errorf("delay = %v, want 1m", d)
+		}
+	})
+
+	t.Run("jitter shortens delay", func(t *testing.T) {
+		p := RetryPolicy{BaseDelay: time.Second, MaxDelay: time.Minute, Jitter: 0.5}
+		for i := 0; i < 100; i++ {
+			d := p.delay(1, provider.ErrRateLimit)
+			if d < 500*time.Millisecond || d > time.Second {
+				t.Fatalf("delay %v outside jitter range", d)
+			}
+		}
+	})
+}
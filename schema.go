@@ -0,0 +1,44 @@
+package promptgen
+
+import (
+	"fmt"
+
+	"github.com/arjunsriva/promptgen/internal/handler"
+	jsonhandler "github.com/arjunsriva/promptgen/internal/json"
+	"github.com/arjunsriva/promptgen/schema"
+)
+
+// Schema returns the JSON Schema that structured outputs are described to
+// the model with and validated against. Outputs of simple types such as
+// string or int have no schema.
+func (g *Generator[I, O]) Schema() (string, error) {
+	h, ok := g.handler.(handler.SchemaHandler)
+	if !ok {
+		var zero O
+		return "", &Error{
+			Err:     ErrConfiguration,
+			Message: fmt.Sprintf("output type %T has no JSON schema", zero),
+			Code:    "no_schema",
+		}
+	}
+	return h.Schema()
+}
+
+// WithSchema replaces the schema generated from the output type, e.g. with
+// a hand-written one from schema.FromString. It replaces any handler set
+// with WithHandler.
+func (g *Generator[I, O]) WithSchema(v *schema.Validator[O]) *Generator[I, O] {
+	g.handler = jsonhandler.NewWithValidator(v)
+	return g
+}
+
+// Validate parses and validates a raw model response exactly as Run would,
+// without calling the provider, so that schemas can be tested against
+// sample responses
+func (g *Generator[I, O]) Validate(response string) (O, error) {
+	output, perr := g.process(response)
+	if perr != nil {
+		return output, perr
+	}
+	return output, nil
+}
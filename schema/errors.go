@@ -0,0 +1,185 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// ValidationError lists every way a JSON document breaks a schema. It is
+// returned by Validator.Validate and reachable with errors.As from the errors
+// generators return.
+type ValidationError struct {
+	Violations []Violation
+}
+
+// Violation is a single schema rule broken by a value
+type Violation struct {
+	// Path is the JSON pointer to the value, e.g. "/tags/0", or "" for the
+	// document itself. For missing and unexpected properties it points to
+	// the property.
+	Path string `json:"path"`
+	// Rule is the JSON Schema keyword that was broken, e.g. "maxLength",
+	// "enum" or "required"
+	Rule string `json:"rule"`
+	// Expected is the rule's constraint, e.g. the maximum length or the
+	// allowed values, when it has one
+	Expected any `json:"expected,omitempty"`
+	// Actual is the offending value, when there is one
+	Actual any `json:"actual,omitempty"`
+	// Message describes the violation
+	Message string `json:"message"`
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		msgs[i] = v.String()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// String formats the violation as "path: message"
+func (v Violation) String() string {
+	if v.Path == "" {
+		return v.Message
+	}
+	return strings.TrimPrefix(v.Path, "/") + ": " + v.Message
+}
+
+// rules maps gojsonschema error types to the JSON Schema keyword they
+// enforce and the detail holding the keyword's value
+var rules = map[string]struct{ keyword, detail string }{
+	"required":                        {"required", ""},
+	"additional_property_not_allowed": {"additionalProperties", ""},
+	"invalid_type":                    {"type", "expected"},
+	"enum":                            {"enum", "allowed"},
+	"const":                           {"const", "allowed"},
+	"string_gte":                      {"minLength", "min"},
+	"string_lte":                      {"maxLength", "max"},
+	"pattern":                         {"pattern", "pattern"},
+	"format":                          {"format", "format"},
+	"number_gte":                      {"minimum", "min"},
+	"number_lte":                      {"maximum", "max"},
+	"number_gt":                       {"exclusiveMinimum", "min"},
+	"number_lt":                       {"exclusiveMaximum", "max"},
+	"multiple_of":                     {"multipleOf", "multiple"},
+	"array_min_items":                 {"minItems", "min"},
+	"array_max_items":                 {"maxItems", "max"},
+	"unique":                          {"uniqueItems", ""},
+	"array_min_properties":            {"minProperties", "min"},
+	"array_max_properties":            {"maxProperties", "max"},
+	"number_any_of":                   {"anyOf", ""},
+	"number_one_of":                   {"oneOf", ""},
+	"number_all_of":                   {"allOf", ""},
+	"number_not":                      {"not", ""},
+}
+
+// newValidationError converts gojsonschema results into a ValidationError
+func newValidationError(errs []gojsonschema.ResultError) *ValidationError {
+	verr := &ValidationError{Violations: make([]Violation, 0, len(errs))}
+	for _, e := range errs {
+		v := Violation{
+			Path:    pointer(e.Context()),
+			Rule:    e.Type(),
+			Actual:  normalize(e.Value()),
+			Message: e.Description(),
+		}
+
+		rule, ok := rules[e.Type()]
+		if ok {
+			v.Rule = rule.keyword
+			if rule.detail != "" {
+				v.Expected = normalize(e.Details()[rule.detail])
+			}
+		}
+
+		switch v.Rule {
+		case "required":
+			// Point at the missing property rather than its parent
+			v.Path += "/" + escape(fmt.Sprint(e.Details()["property"]))
+			v.Actual = nil
+			v.Message = "is required"
+		case "additionalProperties":
+			v.Path += "/" + escape(fmt.Sprint(e.Details()["property"]))
+			v.Expected = false
+			v.Actual = nil
+			v.Message = "is not allowed"
+		case "enum", "const":
+			// The allowed values are reported as a comma separated list of
+			// JSON values, which makes them a JSON array once bracketed
+			if allowed, ok := v.Expected.(string); ok {
+				var values []any
+				if err := json.Unmarshal([]byte("["+allowed+"]"), &values); err == nil {
+					v.Expected = values
+					if v.Rule == "const" && len(values) == 1 {
+						v.Expected = values[0]
+					}
+				}
+			}
+		case "uniqueItems":
+			v.Expected = true
+		}
+
+		verr.Violations = append(verr.Violations, v)
+	}
+	return verr
+}
+
+// pointer converts a gojsonschema context such as (root).tags.0 into a JSON
+// pointer such as /tags/0
+func pointer(ctx *gojsonschema.JsonContext) string {
+	if ctx == nil {
+		return ""
+	}
+
+	// Use a delimiter that cannot clash with property names in practice, so
+	// that names containing dots or slashes survive
+	parts := strings.Split(ctx.String("\x00"), "\x00")
+	var b strings.Builder
+	for _, part := range parts[1:] { // parts[0] is (root)
+		b.WriteString("/")
+		b.WriteString(escape(part))
+	}
+	return b.String()
+}
+
+// escape escapes a property name for use in a JSON pointer
+func escape(name string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(name)
+}
+
+// normalize converts the numbers gojsonschema reports, which may be
+// json.Number, int or *big.Float, into float64 as encoding/json would,
+// recursing into arrays and objects
+func normalize(value any) any {
+	switch v := value.(type) {
+	case json.Number:
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	case int:
+		return float64(v)
+	case *big.Float:
+		f, _ := v.Float64()
+		return f
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = normalize(item)
+		}
+		return out
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, item := range v {
+			out[k] = normalize(item)
+		}
+		return out
+	default:
+		return v
+	}
+}
@@ -0,0 +1,115 @@
+package schema
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidationError(t *testing.T) {
+	validator, err := NewValidator[ValidationFeatures]()
+	if err != nil {
+		t.Fatalf("NewValidator failed: %v", err)
+	}
+
+	err = validator.Validate([]byte(`{
+		"title": "A",
+		"age": -1,
+		"score": 95.7,
+		"tags": ["a", "a"],
+		"scores": [1, 2, 3, 4, 5, 6],
+		"status": "archived",
+		"extra": true
+	}`))
+
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected a *ValidationError, got %T: %v", err, err)
+	}
+
+	want := map[string]Violation{
+		"/title":  {Path: "/title", Rule: "minLength", Expected: 3.0, Actual: "A"},
+		"/age":    {Path: "/age", Rule: "minimum", Expected: 0.0, Actual: -1.0},
+		"/score":  {Path: "/score", Rule: "multipleOf", Expected: 0.5, Actual: 95.7},
+		"/tags":   {Path: "/tags", Rule: "uniqueItems", Expected: true, Actual: []any{"a", "a"}},
+		"/scores": {Path: "/scores", Rule: "maxItems", Expected: 5.0, Actual: []any{1.0, 2.0, 3.0, 4.0, 5.0, 6.0}},
+		"/status": {Path: "/status", Rule: "enum", Expected: []any{"pending", "active", "completed"}, Actual: "archived"},
+		"/extra":  {Path: "/extra", Rule: "additionalProperties", Expected: false},
+	}
+	if len(verr.Violations) != len(want) {
+		t.Errorf("expected %d violations, got %d: %v", len(want), len(verr.Violations), verr)
+	}
+	for _, got := range verr.Violations {
+		w, ok := want[got.Path]
+		if !ok {
+			t.Errorf("unexpected violation: %+v", got)
+			continue
+		}
+		if got.Message == "" {
+			t.Errorf("%s: missing message", got.Path)
+		}
+		got.Message = ""
+		if !reflect.DeepEqual(got, w) {
+			t.Errorf("violation = %#v, want %#v", got, w)
+		}
+	}
+}
+
+func TestValidationErrorPaths(t *testing.T) {
+	type Item struct {
+		Name string `json:"name" jsonschema:"required,maxLength=3"`
+	}
+	type Order struct {
+		Items  []Item          `json:"items" jsonschema:"required"`
+		Counts map[string]int  `json:"counts,omitempty"`
+		Notes  map[string]Item `json:"notes,omitempty"`
+	}
+
+	validator, err := NewValidator[Order]()
+	if err != nil {
+		t.Fatalf("NewValidator failed: %v", err)
+	}
+
+	err = validator.Validate([]byte(`{
+		"items": [{"name": "ok"}, {"name": "too long"}, {}],
+		"counts": {"a/b": "x"}
+	}`))
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected a *ValidationError, got %v", err)
+	}
+
+	got := map[string]string{}
+	for _, v := range verr.Violations {
+		got[v.Path] = v.Rule
+	}
+	want := map[string]string{
+		"/items/1/name": "maxLength",
+		"/items/2/name": "required",
+		"/counts/a~1b":  "type",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("violations = %v, want %v", got, want)
+	}
+
+	if msg := verr.Error(); !strings.Contains(msg, "items/2/name: is required") {
+		t.Errorf("unexpected error message %q", msg)
+	}
+}
+
+func TestViolationString(t *testing.T) {
+	tests := []struct {
+		v    Violation
+		want string
+	}{
+		{Violation{Path: "/title", Message: "is required"}, "title: is required"},
+		{Violation{Path: "/items/0/name", Message: "String length must be less than or equal to 3"}, "items/0/name: String length must be less than or equal to 3"},
+		{Violation{Message: "Invalid type. Expected: object, given: array"}, "Invalid type. Expected: object, given: array"},
+	}
+	for _, tt := range tests {
+		if got := tt.v.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+// Package schema generates JSON Schemas from Go types and validates JSON
+// against them. It is what generators use to describe and check struct
+// outputs and tool arguments, exported so that schemas can be inspected,
+// overridden and tested without calling a model:
+//
+//	v, err := schema.NewValidator[ProductCopy]()
+//	fmt.Println(v.String())
+//	err = v.Validate([]byte(`{"title": "Chair"}`))
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/invopop/jsonschema"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// Validator validates JSON against the schema of T. The schema is generated
+// and compiled once, so a Validator is cheap to reuse and safe for
+// concurrent use.
+type Validator[T any] struct {
+	schema   string
+	compiled *gojsonschema.Schema
+}
+
+// NewValidator generates the schema of T from its json and jsonschema struct
+// tags. Fields are required when tagged jsonschema:"required", and objects
+// do not allow additional properties.
+func NewValidator[T any]() (*Validator[T], error) {
+	var t T
+	typ := reflect.TypeOf(t)
+
+	r := &jsonschema.Reflector{
+		DoNotReference:             true,  // Prevents $ref usage
+		ExpandedStruct:             true,  // Includes all fields
+		AllowAdditionalProperties:  false, // Strict object validation
+		RequiredFromJSONSchemaTags: true,  // Use jsonschema:"required" tag
+	}
+
+	// Reflecting panics on types that have no JSON Schema equivalent
+	var s *jsonschema.Schema
+	var schemaErr error
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				schemaErr = fmt.Errorf("type %T contains unsupported type: %v", t, r)
+			}
+		}()
+		s = r.ReflectFromType(typ)
+	}()
+
+	if schemaErr != nil {
+		return nil, schemaErr
+	}
+
+	// Compile the full schema for validation
+	schemaData, err := json.Marshal(s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal schema: %w", err)
+	}
+	compiled, err := compile(schemaData)
+	if err != nil {
+		return nil, err
+	}
+
+	// Clean up the schema shown to models
+	s.ID = ""           // Remove $id
+	s.Version = ""      // Remove $schema
+	s.Definitions = nil // Remove $defs
+
+	jsonBytes, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal schema: %w", err)
+	}
+
+	return &Validator[T]{
+		schema:   string(jsonBytes),
+		compiled: compiled,
+	}, nil
+}
+
+// FromString creates a validator for T from a hand-written JSON Schema, for
+// constraints that struct tags cannot express. The schema is not checked
+// against T, so keep the two in sync.
+func FromString[T any](schema string) (*Validator[T], error) {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, []byte(schema), "", "  "); err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+
+	compiled, err := compile(buf.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	return &Validator[T]{
+		schema:   buf.String(),
+		compiled: compiled,
+	}, nil
+}
+
+// String returns the JSON Schema, as shown to models
+func (v *Validator[T]) String() string {
+	return v.schema
+}
+
+// Validate checks if the given JSON data matches the schema. Violations are
+// reported as a *ValidationError.
+func (v *Validator[T]) Validate(data []byte) error {
+	result, err := v.compiled.Validate(gojsonschema.NewBytesLoader(data))
+	if err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	if !result.Valid() {
+		return newValidationError(result.Errors())
+	}
+
+	return nil
+}
+
+// ValidateValue checks if value, encoded as JSON, matches the schema. Once
+// decoded, missing and unknown properties can no longer be told apart from
+// zero values, so validate the original JSON with Validate where possible.
+func (v *Validator[T]) ValidateValue(value T) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal value: %w", err)
+	}
+	return v.Validate(data)
+}
+
+// compile compiles a JSON Schema document
+func compile(schema []byte) (*gojsonschema.Schema, error) {
+	compiled, err := gojsonschema.NewSchema(gojsonschema.NewBytesLoader(schema))
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	return compiled, nil
+}
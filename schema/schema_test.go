@@ -3,9 +3,105 @@ package schema
 import (
 	"encoding/json"
 	"strings"
+	"sync"
 	"testing"
 )
 
+type testOutput struct {
+	Response string `json:"response" jsonschema:"required,maxLength=100"`
+}
+
+func TestSchemaValidation(t *testing.T) {
+	validator, err := NewValidator[testOutput]()
+	if err != nil {
+		t.Fatalf("failed to create validator: %v", err)
+	}
+
+	// Test schema generation
+	schema := validator.String()
+	t.Logf("Generated Schema:\n%s", schema)
+
+	// Test response validation
+	validJSON := `{"response": "I'm doing well, thank you for asking!"}`
+	if err := validator.Validate([]byte(validJSON)); err != nil {
+		t.Errorf("Validate failed for valid JSON: %v", err)
+	}
+
+	invalidJSON := `{"response": "This response is way too long and exceeds the maximum length of 100 characters that we specified in the JSON Schema validation rules"}`
+	if err := validator.Validate([]byte(invalidJSON)); err == nil {
+		t.Error("Validate should fail for invalid JSON")
+	} else {
+		t.Logf("Expected error for invalid JSON: %v", err)
+	}
+
+	// Test missing required field
+	emptyJSON := `{}`
+	if err := validator.Validate([]byte(emptyJSON)); err == nil {
+		t.Error("Validate should fail for missing required field")
+	} else {
+		t.Logf("Expected error for missing field: %v", err)
+	}
+}
+
+func TestValidatorErrors(t *testing.T) {
+	type InvalidType struct {
+		Channel chan int // channels can't be converted to JSON Schema
+	}
+
+	_, err := NewValidator[InvalidType]()
+	if err != nil {
+		t.Logf("Expected error for invalid type: %v", err)
+	} else {
+		t.Error("expected error for invalid type, got nil")
+	}
+}
+
+func TestValidatorConcurrent(t *testing.T) {
+	validator, err := NewValidator[testOutput]()
+	if err != nil {
+		t.Fatalf("failed to create validator: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := validator.Validate([]byte(`{"response": "ok"}`)); err != nil {
+				t.Errorf("Validate failed for valid JSON: %v", err)
+			}
+			if err := validator.Validate([]byte(`{}`)); err == nil {
+				t.Error("Validate should fail for missing required field")
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestFromString(t *testing.T) {
+	validator, err := FromString[testOutput](`{"type": "object", "properties": {"response": {"enum": ["yes", "no"]}}, "required": ["response"]}`)
+	if err != nil {
+		t.Fatalf("FromString failed: %v", err)
+	}
+
+	if !strings.Contains(validator.String(), "\n  ") {
+		t.Errorf("schema should be indented, got %s", validator.String())
+	}
+	if err := validator.ValidateValue(testOutput{Response: "yes"}); err != nil {
+		t.Errorf("ValidateValue failed for valid value: %v", err)
+	}
+	if err := validator.ValidateValue(testOutput{Response: "maybe"}); err == nil {
+		t.Error("ValidateValue should fail for a value outside the enum")
+	}
+
+	if _, err := FromString[testOutput](`{"type": `); err == nil {
+		t.Error("expected error for malformed JSON")
+	}
+	if _, err := FromString[testOutput](`{"type": "nonsense"}`); err == nil {
+		t.Error("expected error for an invalid schema")
+	}
+}
+
 type TestStruct struct {
 	Required   string   `json:"required_field" jsonschema:"required,title=Required Field,description=This field is required"`
 	Optional   string   `json:"optional_field,omitempty" jsonschema:"maxLength=10,title=Optional Field"`
@@ -64,10 +160,7 @@ func TestSchemaGeneration(t *testing.T) {
 		t.Fatalf("NewValidator failed: %v", err)
 	}
 
-	schema, err := validator.SchemaString()
-	if err != nil {
-		t.Fatalf("SchemaString failed: %v", err)
-	}
+	schema := validator.String()
 
 	t.Logf("Generated Schema:\n%s", schema)
 }
@@ -78,10 +171,7 @@ func TestProductCopySchema(t *testing.T) {
 		t.Fatalf("NewValidator failed: %v", err)
 	}
 
-	schema, err := validator.SchemaString()
-	if err != nil {
-		t.Fatalf("SchemaString failed: %v", err)
-	}
+	schema := validator.String()
 
 	// Parse the schema to verify its structure
 	var schemaMap map[string]interface{}
@@ -113,10 +203,7 @@ func TestReviewSummarySchema(t *testing.T) {
 		t.Fatalf("NewValidator failed: %v", err)
 	}
 
-	schema, err := validator.SchemaString()
-	if err != nil {
-		t.Fatalf("SchemaString failed: %v", err)
-	}
+	schema := validator.String()
 
 	var schemaMap map[string]interface{}
 	if err := json.Unmarshal([]byte(schema), &schemaMap); err != nil {
@@ -328,3 +415,59 @@ func TestValidationFeatures(t *testing.T) {
 		})
 	}
 }
+
+type benchmarkOutput struct {
+	Title       string   `json:"title" jsonschema:"required,maxLength=60"`
+	Description string   `json:"description" jsonschema:"required,maxLength=160"`
+	Tags        []string `json:"tags" jsonschema:"maxItems=5"`
+	Score       float64  `json:"score" jsonschema:"minimum=0,maximum=1"`
+}
+
+const benchmarkJSON = `{"title": "Ergonomic Chair", "description": "Adjustable height and lumbar support", "tags": ["office", "furniture"], "score": 0.9}`
+
+// BenchmarkValidate measures validation with the schema compiled once
+func BenchmarkValidate(b *testing.B) {
+	validator, err := NewValidator[benchmarkOutput]()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := validator.Validate([]byte(benchmarkJSON)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkValidateParallel measures validation shared across goroutines
+func BenchmarkValidateParallel(b *testing.B) {
+	validator, err := NewValidator[benchmarkOutput]()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if err := validator.Validate([]byte(benchmarkJSON)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// BenchmarkValidateUncached reflects and compiles the schema for every
+// validation, which is what Validate did before the schema was cached
+func BenchmarkValidateUncached(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		validator, err := NewValidator[benchmarkOutput]()
+		if err != nil {
+			b.Fatal(err)
+		}
+		if err := validator.Validate([]byte(benchmarkJSON)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
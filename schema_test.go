@@ -0,0 +1,94 @@
+package promptgen
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/arjunsriva/promptgen/provider"
+	"github.com/arjunsriva/promptgen/schema"
+)
+
+func TestGeneratorSchema(t *testing.T) {
+	gen, _ := Create[TestInput, TestOutput]("Hello {{.Message}}")
+
+	s, err := gen.Schema()
+	if err != nil {
+		t.Fatalf("Schema() error = %v", err)
+	}
+	var parsed map[string]any
+	if err := json.Unmarshal([]byte(s), &parsed); err != nil {
+		t.Fatalf("invalid schema JSON: %v", err)
+	}
+	if _, ok := parsed["properties"].(map[string]any)["response"]; !ok {
+		t.Errorf("expected the response property, got %s", s)
+	}
+
+	stringGen, _ := Create[string, string]("Hello {{.}}")
+	if _, err := stringGen.Schema(); err == nil {
+		t.Error("expected error for an output type without a schema")
+	}
+}
+
+func TestGeneratorValidate(t *testing.T) {
+	type Headline struct {
+		Title string `json:"title" jsonschema:"required,maxLength=10"`
+	}
+	gen, _ := Create[TestInput, Headline]("Write a headline about {{.Message}}")
+
+	output, err := gen.Validate("```json\n{\"title\": \"Hi\"}\n```")
+	if err != nil || output.Title != "Hi" {
+		t.Errorf("Validate() = %+v, %v", output, err)
+	}
+	if _, err := gen.Validate(`{"title": "Far too long for a headline"}`); !IsValidation(err) {
+		t.Errorf("expected validation error, got %v", err)
+	}
+	if _, err := gen.Validate(`not json`); err == nil || IsValidation(err) {
+		t.Errorf("expected parse error, got %v", err)
+	}
+
+	// Missing and unknown properties are checked on the response as sent,
+	// not on the decoded struct where they are indistinguishable from ""
+	_, err = gen.Validate("```json\n{\"bogus\": 1}\n```")
+	if !IsValidation(err) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+	for _, want := range []string{"title: ", "required", "bogus: "} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+
+	// The same checks apply to Run
+	mock := &provider.MockProvider{Response: `{"title": "Hi", "subtitle": "Hello"}`}
+	gen.WithProvider(mock)
+	if _, err := gen.Run(context.Background(), TestInput{Message: "Go"}); !IsValidation(err) || !strings.Contains(err.Error(), "subtitle") {
+		t.Errorf("expected the unknown property to fail validation, got %v", err)
+	}
+}
+
+func TestWithSchema(t *testing.T) {
+	v, err := schema.FromString[TestOutput](`{
+		"type": "object",
+		"properties": {"response": {"type": "string", "pattern": "^[A-Z]"}},
+		"required": ["response"]
+	}`)
+	if err != nil {
+		t.Fatalf("FromString() error = %v", err)
+	}
+
+	mock := &provider.MockProvider{Response: `{"response": "hello"}`}
+	gen, _ := Create[TestInput, TestOutput]("Hello {{.Message}}")
+	gen.WithProvider(mock).WithSchema(v)
+
+	if _, err := gen.Run(context.Background(), TestInput{Message: "test"}); !IsValidation(err) {
+		t.Errorf("expected the custom schema to reject the response, got %v", err)
+	}
+	if !strings.Contains(mock.Prompts[0], `"pattern": "^[A-Z]"`) {
+		t.Errorf("expected the custom schema in the prompt, got %q", mock.Prompts[0])
+	}
+	if s, _ := gen.Schema(); s != v.String() {
+		t.Errorf("Schema() = %s, want the custom schema", s)
+	}
+}
@@ -1,63 +1,118 @@
 package promptgen
 
 import (
-	"bytes"
 	"context"
 	"fmt"
+	"strings"
 )
 
-// Stream represents a real-time stream of content from the AI provider
-type Stream struct {
+// Stream represents a real-time stream of content from the AI provider.
+// Content is closed when the stream ends and any error is sent on Err. Done
+// is closed last on every exit path, so callers can wait on it alone.
+type Stream[O any] struct {
 	Content chan string
 	Err     chan error
 	Done    chan struct{}
+
+	finished chan struct{} // closed once output and err are set
+	output   O
+	err      error
+}
+
+// Result blocks until the stream ends and returns the accumulated response
+// parsed and validated exactly as Run would. Content that has not been read
+// yet is discarded.
+func (s *Stream[O]) Result() (O, error) {
+	for range s.Content {
+	}
+	<-s.finished
+	return s.output, s.err
 }
 
 // Stream provides real-time streaming of the generated content
-func (g *Generator[I, O]) Stream(ctx context.Context, input I) (*Stream, error) {
+func (g *Generator[I, O]) Stream(ctx context.Context, input I) (*Stream[O], error) {
 	if err := g.ensureDefaultConfig(); err != nil {
 		return nil, err
 	}
 
-	// Apply timeout if set
+	// Apply timeout if set, releasing it once the stream ends
+	cancel := func() {}
 	if g.timeout > 0 {
-		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, g.timeout)
-		defer cancel()
 	}
 
-	var buf bytes.Buffer
-	if err := g.prompt.Execute(&buf, input); err != nil {
-		return nil, fmt.Errorf("failed to execute template: %w", err)
+	req, err := g.request(ctx, input)
+	if err != nil {
+		cancel()
+		return nil, err
 	}
-
-	// Wrap prompt with type-specific instructions
-	wrappedPrompt := g.handler.WrapPrompt(buf.String())
+	req.Tools = nil // Streams do not call tools
 
 	// Run before hooks
-	for _, hook := range g.hooks {
-		var err error
-		wrappedPrompt, err = hook.BeforeRequest(ctx, wrappedPrompt)
-		if err != nil {
+	req, err = g.applyBeforeHooks(ctx, req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	for _, hook := range g.streamHooks {
+		if err := hook.OnStreamStart(ctx, req.Prompt()); err != nil {
+			cancel()
 			return nil, fmt.Errorf("hook error: %w", err)
 		}
 	}
 
-	contentChan, errChan, err := g.provider.Stream(ctx, wrappedPrompt)
+	contentChan, errChan, err := g.provider.Stream(ctx, req)
 	if err != nil {
-		return nil, fmt.Errorf("provider stream failed: %w", err)
+		cancel()
+		return nil, fmt.Errorf("provider stream failed: %w", providerError(ctx, err))
 	}
 
-	stream := &Stream{
-		Content: make(chan string),
-		Err:     make(chan error, 1), // Buffer error channel to prevent blocking
-		Done:    make(chan struct{}),
+	stream := &Stream[O]{
+		Content:  make(chan string),
+		Err:      make(chan error, 1), // Buffer error channel to prevent blocking
+		Done:     make(chan struct{}),
+		finished: make(chan struct{}),
 	}
 
 	go func() {
+		var raw, full strings.Builder
+		var streamErr error
+
 		defer func() {
+			for _, hook := range g.streamHooks {
+				if err := hook.OnStreamEnd(ctx, raw.String(), streamErr); err != nil && streamErr == nil {
+					streamErr = fmt.Errorf("hook error: %w", err)
+				}
+			}
+
+			// Hooks without stream support see the full text once, which is
+			// what Result parses; chunks already delivered stay unchanged
+			text := full.String()
+			for _, hook := range g.hooks {
+				if _, ok := hook.(StreamHook); ok {
+					continue
+				}
+				var err error
+				text, err = hook.AfterResponse(ctx, text, streamErr)
+				if err != nil && streamErr == nil {
+					streamErr = fmt.Errorf("hook error: %w", err)
+				}
+			}
+
+			cancel()
 			close(stream.Content)
+			if streamErr != nil {
+				stream.Err <- streamErr
+				stream.err = providerError(ctx, streamErr)
+			} else {
+				var perr *Error
+				if stream.output, perr = g.process(text); perr != nil {
+					stream.err = perr
+				}
+			}
 			close(stream.Err)
+			close(stream.finished)
 			close(stream.Done)
 		}()
 
@@ -65,44 +120,50 @@ func (g *Generator[I, O]) Stream(ctx context.Context, input I) (*Stream, error)
 			select {
 			case <-ctx.Done():
 				// Always prioritize context cancellation
-				stream.Err <- ctx.Err()
+				streamErr = ctx.Err()
 				return
 			case content, ok := <-contentChan:
 				if !ok {
 					// Channel closed - check for errors
 					select {
-					case err := <-errChan:
-						stream.Err <- err
-						return
+					case err, ok := <-errChan:
+						if ok && err != nil {
+							streamErr = err
+						}
 					default:
-						// Only signal done if no errors
-						stream.Done <- struct{}{}
-						return
 					}
+					return
 				}
 
 				// Process content through hooks
-				for _, hook := range g.hooks {
+				raw.WriteString(content)
+				for _, hook := range g.streamHooks {
 					var err error
-					content, err = hook.AfterResponse(ctx, content, nil)
+					content, err = hook.OnChunk(ctx, content)
 					if err != nil {
-						stream.Err <- fmt.Errorf("hook error: %w", err)
+						streamErr = fmt.Errorf("hook error: %w", err)
 						return
 					}
 				}
+				if content == "" {
+					continue
+				}
+				full.WriteString(content)
 
 				// Send processed content
 				select {
 				case stream.Content <- content:
 				case <-ctx.Done():
-					stream.Err <- ctx.Err()
+					streamErr = ctx.Err()
 					return
 				}
 			case err, ok := <-errChan:
 				if !ok {
+					// Stop selecting on a closed channel
+					errChan = nil
 					continue
 				}
-				stream.Err <- err
+				streamErr = err
 				return
 			}
 		}
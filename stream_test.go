@@ -16,6 +16,11 @@ import (
 type streamTestHook struct {
 	beforePrefix string
 	afterSuffix  string
+
+	startPrompt string
+	endText     string
+	endErr      error
+	afterCalls  int
 }
 
 func (h *streamTestHook) BeforeRequest(ctx context.Context, prompt string) (string, error) {
@@ -23,7 +28,60 @@ func (h *streamTestHook) BeforeRequest(ctx context.Context, prompt string) (stri
 }
 
 func (h *streamTestHook) AfterResponse(ctx context.Context, response string, err error) (string, error) {
-	return response + " " + h.afterSuffix, err
+	h.afterCalls++
+	return response, err
+}
+
+func (h *streamTestHook) OnStreamStart(ctx context.Context, prompt string) error {
+	h.startPrompt = prompt
+	return nil
+}
+
+func (h *streamTestHook) OnChunk(ctx context.Context, chunk string) (string, error) {
+	return chunk + " " + h.afterSuffix, nil
+}
+
+func (h *streamTestHook) OnStreamEnd(ctx context.Context, fullText string, err error) error {
+	h.endText = fullText
+	h.endErr = err
+	return nil
+}
+
+// redactingHook fails streams whose full text contains a secret
+type redactingHook struct {
+	secret string
+}
+
+func (h *redactingHook) OnStreamStart(context.Context, string) error {
+	return nil
+}
+
+func (h *redactingHook) OnChunk(_ context.Context, chunk string) (string, error) {
+	return chunk, nil
+}
+
+func (h *redactingHook) OnStreamEnd(_ context.Context, fullText string, _ error) error {
+	if strings.Contains(fullText, h.secret) {
+		return errors.New("secret leaked")
+	}
+	return nil
+}
+
+// suffixHook only implements Hook, appending suffix to every response
+type suffixHook struct {
+	suffix string
+	err    error
+}
+
+func (h *suffixHook) BeforeRequest(_ context.Context, prompt string) (string, error) {
+	return prompt, nil
+}
+
+func (h *suffixHook) AfterResponse(_ context.Context, response string, err error) (string, error) {
+	if h.err != nil {
+		return response, h.err
+	}
+	return response + h.suffix, err
 }
 
 func TestStream(t *testing.T) {
@@ -95,10 +153,15 @@ func TestStream(t *testing.T) {
 			if err == nil {
 				t.Error("expected error from stream")
 			}
+		case <-time.After(time.Second):
+			t.Fatal("stream did not report the error")
+		}
+
+		// Done is closed after errors too, so waiting on it never blocks
+		select {
 		case <-stream.Done:
-			t.Error("stream completed despite error")
 		case <-time.After(time.Second):
-			t.Error("stream did not complete")
+			t.Error("Done not closed after error")
 		}
 	})
 
@@ -140,5 +203,176 @@ func TestStream(t *testing.T) {
 		} else if !strings.HasPrefix(mock.Prompts[0], "modified") {
 			t.Errorf("before hook was not applied, got prompt: %q", mock.Prompts[0])
 		}
+
+		// Verify the stream hook saw the whole stream, and AfterResponse was not used
+		if hook.startPrompt != mock.Prompts[0] {
+			t.Errorf("OnStreamStart prompt = %q, want %q", hook.startPrompt, mock.Prompts[0])
+		}
+		if hook.endText != "Helloworld" || hook.endErr != nil {
+			t.Errorf("OnStreamEnd got %q, %v", hook.endText, hook.endErr)
+		}
+		if hook.afterCalls != 0 {
+			t.Errorf("AfterResponse called %d times during stream", hook.afterCalls)
+		}
+	})
+
+	t.Run("response-only hook runs at stream end", func(t *testing.T) {
+		mock := &provider.MockProvider{StreamTokens: []string{"the key is sk-", "12345"}}
+		hook := &suffixHook{suffix: " [checked]"}
+
+		gen, _ := Create[string, string]("test")
+		gen.WithProvider(mock).WithHook(hook)
+
+		stream, err := gen.Stream(context.Background(), "test")
+		if err != nil {
+			t.Fatalf("Stream() error = %v", err)
+		}
+		result, err := stream.Result()
+		if err != nil {
+			t.Fatalf("Result() error = %v", err)
+		}
+		if result != "the key is sk-12345 [checked]" {
+			t.Errorf("Result() = %q, want the AfterResponse output", result)
+		}
+
+		hook.err = errors.New("secret leaked")
+		stream, _ = gen.Stream(context.Background(), "test")
+		if _, err := stream.Result(); err == nil || !strings.Contains(err.Error(), "secret leaked") {
+			t.Errorf("expected hook error, got %v", err)
+		}
+	})
+
+	t.Run("stream hook sees text across chunks", func(t *testing.T) {
+		mock := &provider.MockProvider{
+			StreamTokens: []string{"the key is sk-", "12345"},
+		}
+
+		gen, _ := Create[string, string]("test")
+		gen.WithProvider(mock).WithStreamHook(&redactingHook{secret: "sk-12345"})
+
+		stream, err := gen.Stream(context.Background(), "test")
+		if err != nil {
+			t.Fatalf("Stream() error = %v", err)
+		}
+		for range stream.Content {
+		}
+
+		if err := <-stream.Err; err == nil || !strings.Contains(err.Error(), "secret leaked") {
+			t.Errorf("expected hook error, got %v", err)
+		}
+	})
+}
+
+func TestStreamResult(t *testing.T) {
+	t.Run("parses and validates the full response", func(t *testing.T) {
+		mock := &provider.MockProvider{
+			StreamTokens: []string{`{"respo`, `nse": "Hello`, ` world"}`},
+		}
+
+		gen, _ := Create[TestInput, TestOutput]("test")
+		gen.WithProvider(mock)
+
+		stream, err := gen.Stream(context.Background(), TestInput{Message: "test"})
+		if err != nil {
+			t.Fatalf("Stream() error = %v", err)
+		}
+
+		var chunks []string
+		for chunk := range stream.Content {
+			chunks = append(chunks, chunk)
+		}
+
+		result, err := stream.Result()
+		if err != nil {
+			t.Fatalf("Result() error = %v", err)
+		}
+		if result.Response != "Hello world" {
+			t.Errorf("Result() = %q, want 'Hello world'", result.Response)
+		}
+		if len(chunks) != 3 {
+			t.Errorf("expected 3 chunks, got %d", len(chunks))
+		}
+	})
+
+	t.Run("without reading content", func(t *testing.T) {
+		mock := &provider.MockProvider{StreamTokens: []string{"4", "2"}}
+
+		gen, _ := Create[string, int]("test")
+		gen.WithProvider(mock)
+
+		stream, err := gen.Stream(context.Background(), "test")
+		if err != nil {
+			t.Fatalf("Stream() error = %v", err)
+		}
+
+		result, err := stream.Result()
+		if err != nil || result != 42 {
+			t.Errorf("Result() = %v, %v, want 42", result, err)
+		}
+	})
+
+	t.Run("validation failure", func(t *testing.T) {
+		mock := &provider.MockProvider{StreamTokens: []string{`{"response": `, `"far too long"}`}}
+
+		gen, _ := Create[TestInput, repairTestOutput]("test")
+		gen.WithProvider(mock)
+
+		stream, _ := gen.Stream(context.Background(), TestInput{Message: "test"})
+		_, err := stream.Result()
+
+		var perr *Error
+		if !errors.As(err, &perr) || perr.Code != "validation_failed" || !IsValidation(err) {
+			t.Errorf("expected validation *Error, got %v", err)
+		}
+	})
+
+	t.Run("parse failure", func(t *testing.T) {
+		mock := &provider.MockProvider{StreamTokens: []string{"not ", "json"}}
+
+		gen, _ := Create[TestInput, TestOutput]("test")
+		gen.WithProvider(mock)
+
+		stream, _ := gen.Stream(context.Background(), TestInput{Message: "test"})
+		_, err := stream.Result()
+
+		var perr *Error
+		if !errors.As(err, &perr) || perr.Code != "parse_failed" || !errors.Is(err, ErrInvalidResponse) {
+			t.Errorf("expected parse *Error, got %v", err)
+		}
+	})
+
+	t.Run("provider error", func(t *testing.T) {
+		mock := &provider.MockProvider{Errors: []error{provider.ErrRateLimit}}
+
+		gen, _ := Create[TestInput, TestOutput]("test")
+		gen.WithProvider(mock)
+
+		stream, _ := gen.Stream(context.Background(), TestInput{Message: "test"})
+		if _, err := stream.Result(); !errors.Is(err, ErrRateLimit) {
+			t.Errorf("expected rate limit error, got %v", err)
+		}
+	})
+
+	t.Run("timeout spans the stream", func(t *testing.T) {
+		mock := &provider.MockProvider{
+			StreamTokens: []string{`{"response": `, `"Hello"}`},
+			DelayMs:      5,
+		}
+
+		gen, _ := Create[TestInput, TestOutput]("test")
+		gen.WithProvider(mock).WithTimeout(time.Second)
+
+		stream, _ := gen.Stream(context.Background(), TestInput{Message: "test"})
+		if _, err := stream.Result(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		mock.DelayMs = 100
+		gen.WithTimeout(20 * time.Millisecond)
+
+		stream, _ = gen.Stream(context.Background(), TestInput{Message: "test"})
+		if _, err := stream.Result(); !IsTimeout(err) {
+			t.Errorf("expected timeout error, got %v", err)
+		}
 	})
 }
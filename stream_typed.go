@@ -0,0 +1,106 @@
+package promptgen
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	"github.com/arjunsriva/promptgen/internal/handler"
+)
+
+// TypedStream delivers progressively filled snapshots of a structured output.
+// Partial is closed when the stream ends and any error is sent on Err. Done
+// is closed last on every exit path.
+type TypedStream[O any] struct {
+	Partial chan O
+	Err     chan error
+	Done    chan struct{}
+
+	finished chan struct{} // closed once output and err are set
+	output   O
+	err      error
+}
+
+// Result blocks until the stream ends and returns the final validated value.
+// Snapshots that have not been read yet are discarded.
+func (s *TypedStream[O]) Result() (O, error) {
+	for range s.Partial {
+	}
+	<-s.finished
+	return s.output, s.err
+}
+
+// StreamTyped streams the response and emits a snapshot of O on Partial each
+// time more of it can be parsed, so fields can be rendered while later ones are
+// still being generated. Partial snapshots are not validated. Once the response
+// is complete it is parsed and validated like Run, and the final value is sent
+// as the last snapshot.
+func (g *Generator[I, O]) StreamTyped(ctx context.Context, input I) (*TypedStream[O], error) {
+	stream, err := g.Stream(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	typed := &TypedStream[O]{
+		Partial:  make(chan O),
+		Err:      make(chan error, 1),
+		Done:     make(chan struct{}),
+		finished: make(chan struct{}),
+	}
+
+	partial, _ := g.handler.(handler.PartialParser[O])
+
+	go func() {
+		defer func() {
+			close(typed.Partial)
+			if typed.err != nil {
+				typed.Err <- typed.err
+			}
+			close(typed.Err)
+			close(typed.finished)
+			close(typed.Done)
+		}()
+
+		var (
+			full strings.Builder
+			last O
+			sent bool
+		)
+		send := func(value O) bool {
+			select {
+			case typed.Partial <- value:
+				return true
+			case <-ctx.Done():
+				typed.err = ctx.Err()
+				return false
+			}
+		}
+
+		for chunk := range stream.Content {
+			full.WriteString(chunk)
+			if partial == nil {
+				continue
+			}
+
+			value, ok := partial.ParsePartial(full.String())
+			if !ok || (sent && reflect.DeepEqual(value, last)) {
+				continue
+			}
+			if !send(value) {
+				return
+			}
+			last, sent = value, true
+		}
+
+		output, err := stream.Result()
+		if err != nil {
+			typed.err = err
+			return
+		}
+		if send(output) {
+			typed.output = output
+		}
+	}()
+
+	return typed, nil
+}
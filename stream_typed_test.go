@@ -0,0 +1,105 @@
+package promptgen
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/arjunsriva/promptgen/provider"
+)
+
+type typedStreamOutput struct {
+	Title       string `json:"title" jsonschema:"required,maxLength=20"`
+	Description string `json:"description" jsonschema:"required"`
+}
+
+func TestStreamTyped(t *testing.T) {
+	t.Run("emits progressive snapshots", func(t *testing.T) {
+		mock := &provider.MockProvider{
+			StreamTokens: []string{`{"title": "Ergo`, `nomic Chair", `, `"description": "Comfy`, ` and adjustable"}`},
+		}
+
+		gen, _ := Create[TestInput, typedStreamOutput]("test")
+		gen.WithProvider(mock)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		stream, err := gen.StreamTyped(ctx, TestInput{Message: "test"})
+		if err != nil {
+			t.Fatalf("StreamTyped() error = %v", err)
+		}
+
+		var snapshots []typedStreamOutput
+		for value := range stream.Partial {
+			snapshots = append(snapshots, value)
+		}
+		if err := <-stream.Err; err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		select {
+		case <-stream.Done:
+		default:
+			t.Error("expected Done to be closed")
+		}
+
+		if len(snapshots) < 3 {
+			t.Fatalf("expected several snapshots, got %+v", snapshots)
+		}
+		if snapshots[0].Title != "Ergo" || snapshots[0].Description != "" {
+			t.Errorf("unexpected first snapshot: %+v", snapshots[0])
+		}
+		if snapshots[2].Title != "Ergonomic Chair" || snapshots[2].Description != "Comfy" {
+			t.Errorf("expected title while description streams, got %+v", snapshots[2])
+		}
+		final := snapshots[len(snapshots)-1]
+		if final.Description != "Comfy and adjustable" {
+			t.Errorf("unexpected final value: %+v", final)
+		}
+	})
+
+	t.Run("final validation failure", func(t *testing.T) {
+		mock := &provider.MockProvider{
+			StreamTokens: []string{`{"title": "A title that is far too long", `, `"description": "x"}`},
+		}
+
+		gen, _ := Create[TestInput, typedStreamOutput]("test")
+		gen.WithProvider(mock)
+
+		stream, err := gen.StreamTyped(context.Background(), TestInput{Message: "test"})
+		if err != nil {
+			t.Fatalf("StreamTyped() error = %v", err)
+		}
+
+		for range stream.Partial {
+		}
+		if err := <-stream.Err; !IsValidation(err) {
+			t.Errorf("expected validation error, got %v", err)
+		}
+		select {
+		case <-stream.Done:
+		case <-time.After(time.Second):
+			t.Error("expected Done to be closed after an error")
+		}
+	})
+
+	t.Run("primitive output", func(t *testing.T) {
+		mock := &provider.MockProvider{StreamTokens: []string{"4", "2"}}
+
+		gen, _ := Create[string, int]("test")
+		gen.WithProvider(mock)
+
+		stream, err := gen.StreamTyped(context.Background(), "test")
+		if err != nil {
+			t.Fatalf("StreamTyped() error = %v", err)
+		}
+
+		var values []int
+		for value := range stream.Partial {
+			values = append(values, value)
+		}
+		if len(values) != 1 || values[0] != 42 {
+			t.Errorf("expected only the final value 42, got %v", values)
+		}
+	})
+}
@@ -0,0 +1,213 @@
+package promptgen
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/arjunsriva/promptgen/provider"
+	"github.com/arjunsriva/promptgen/schema"
+)
+
+// defaultMaxToolRounds limits how many times in a row the model may call tools
+const defaultMaxToolRounds = 10
+
+// ToolCaller is a function the model can call, such as a Tool
+type ToolCaller interface {
+	// Definition describes the function to the model
+	Definition() provider.Tool
+
+	// Call runs the function with JSON encoded arguments and returns the
+	// result to send back to the model
+	Call(ctx context.Context, arguments string) (string, error)
+}
+
+// Tool is a typed Go function the model can call while generating a
+// response. The model is given the JSON Schema of A, and the arguments it
+// supplies are validated against it before Func is called. Use NewTool to
+// catch schema errors up front, or set the fields directly and the schema
+// is generated on first use.
+type Tool[A any, R any] struct {
+	Name        string
+	Description string
+	Func        func(ctx context.Context, args A) (R, error)
+
+	once       sync.Once
+	validator  *schema.Validator[A]
+	parameters json.RawMessage
+	err        error
+}
+
+// NewTool creates a tool from a Go function, generating the argument schema
+// from A the same way output schemas are generated
+func NewTool[A any, R any](name, description string, fn func(ctx context.Context, args A) (R, error)) (*Tool[A, R], error) {
+	t := &Tool[A, R]{
+		Name:        name,
+		Description: description,
+		Func:        fn,
+	}
+	if err := t.init(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// init generates the argument schema the first time the tool is used
+func (t *Tool[A, R]) init() error {
+	t.once.Do(func() {
+		t.validator, t.err = schema.NewValidator[A]()
+		if t.err != nil {
+			t.err = fmt.Errorf("invalid arguments for tool %s: %w", t.Name, t.err)
+			return
+		}
+		t.parameters = json.RawMessage(t.validator.String())
+	})
+	return t.err
+}
+
+// Definition describes the tool to the model
+func (t *Tool[A, R]) Definition() provider.Tool {
+	t.init() // Call reports the error if the schema is invalid
+	return provider.Tool{
+		Name:        t.Name,
+		Description: t.Description,
+		Parameters:  t.parameters,
+	}
+}
+
+// Call validates and decodes the arguments, runs the function and encodes
+// its result as JSON. String results are returned as is.
+func (t *Tool[A, R]) Call(ctx context.Context, arguments string) (string, error) {
+	if err := t.init(); err != nil {
+		return "", err
+	}
+	if t.Func == nil {
+		return "", fmt.Errorf("tool %s has no function", t.Name)
+	}
+
+	if arguments == "" {
+		arguments = "{}"
+	}
+	if err := t.validator.Validate([]byte(arguments)); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	var args A
+	if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	result, err := t.Func(ctx, args)
+	if err != nil {
+		return "", err
+	}
+
+	if s, ok := any(result).(string); ok {
+		return s, nil
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal result: %w", err)
+	}
+	return string(data), nil
+}
+
+// WithTool makes tools available to the model. When the model calls one, Run
+// executes it and sends back the result, repeating until the model answers.
+// Streams do not call tools.
+func (g *Generator[I, O]) WithTool(tools ...ToolCaller) *Generator[I, O] {
+	g.tools = append(g.tools, tools...)
+	return g
+}
+
+// WithMaxToolRounds limits how many times in a row the model may call tools
+// before Run gives up. The default is 10.
+func (g *Generator[I, O]) WithMaxToolRounds(rounds int) *Generator[I, O] {
+	g.maxToolRounds = rounds
+	return g
+}
+
+// toolDefinitions describes the registered tools to the provider
+func (g *Generator[I, O]) toolDefinitions() []provider.Tool {
+	var defs []provider.Tool
+	for _, tool := range g.tools {
+		defs = append(defs, tool.Definition())
+	}
+	return defs
+}
+
+// completeWithTools sends req and runs any tools the model calls, feeding the
+// results back until the model answers. It returns the final response along
+// with the request extended by the tool exchange, recording every call in result.
+func (g *Generator[I, O]) completeWithTools(ctx context.Context, req provider.Request, result *Result[O]) (provider.Request, provider.Response, error) {
+	maxRounds := g.maxToolRounds
+	if maxRounds == 0 {
+		maxRounds = defaultMaxToolRounds
+	}
+
+	for round := 0; ; round++ {
+		response, err := g.complete(ctx, req)
+		if err != nil {
+			return req, response, err
+		}
+		result.record(response, g.pricingTable())
+
+		if len(response.ToolCalls) == 0 {
+			return req, response, nil
+		}
+		if round >= maxRounds {
+			return req, response, &Error{
+				Err:     ErrInvalidResponse,
+				Message: fmt.Sprintf("model kept calling tools after %d rounds", maxRounds),
+				Code:    "tool_rounds_exceeded",
+			}
+		}
+
+		req, err = g.callTools(ctx, req, response)
+		if err != nil {
+			return req, response, err
+		}
+	}
+}
+
+// callTools runs the tools requested in response and returns req extended with
+// the model's request and the results. Tool errors are reported back to the
+// model so that it can correct its arguments.
+func (g *Generator[I, O]) callTools(ctx context.Context, req provider.Request, response provider.Response) (provider.Request, error) {
+	messages := make([]provider.Message, 0, len(req.Messages)+len(response.ToolCalls)+1)
+	messages = append(messages, req.Messages...)
+	messages = append(messages, provider.Message{
+		Role:      provider.RoleAssistant,
+		Content:   response.Content,
+		ToolCalls: response.ToolCalls,
+	})
+
+	for _, call := range response.ToolCalls {
+		content, err := g.callTool(ctx, call)
+		if ctx.Err() != nil {
+			return req, providerError(ctx, ctx.Err())
+		}
+		if err != nil {
+			content = fmt.Sprintf("error: %v", err)
+		}
+		messages = append(messages, provider.Message{
+			Role:       provider.RoleTool,
+			Content:    content,
+			ToolCallID: call.ID,
+		})
+	}
+
+	req.Messages = messages
+	return req, nil
+}
+
+// callTool runs a single tool call
+func (g *Generator[I, O]) callTool(ctx context.Context, call provider.ToolCall) (string, error) {
+	for _, tool := range g.tools {
+		if tool.Definition().Name == call.Name {
+			return tool.Call(ctx, call.Arguments)
+		}
+	}
+	return "", fmt.Errorf("unknown tool %q", call.Name)
+}
@@ -0,0 +1,232 @@
+package promptgen
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/arjunsriva/promptgen/provider"
+)
+
+type weatherArgs struct {
+	City string `json:"city" jsonschema:"required,minLength=1"`
+}
+
+type weatherReport struct {
+	City        string `json:"city"`
+	Temperature int    `json:"temperature"`
+}
+
+type weatherAnswer struct {
+	Summary string `json:"summary" jsonschema:"required"`
+}
+
+func newWeatherTool(t *testing.T, calls *[]string) *Tool[weatherArgs, weatherReport] {
+	t.Helper()
+
+	tool, err := NewTool("get_weather", "Get the current temperature in a city",
+		func(_ context.Context, args weatherArgs) (weatherReport, error) {
+			*calls = append(*calls, args.City)
+			if args.City == "Atlantis" {
+				return weatherReport{}, errors.New("city not found")
+			}
+			return weatherReport{City: args.City, Temperature: 21}, nil
+		})
+	if err != nil {
+		t.Fatalf("NewTool() error = %v", err)
+	}
+	return tool
+}
+
+// toolCall is a mock response asking for the given tool calls
+func toolCall(calls ...provider.ToolCall) provider.Response {
+	return provider.Response{
+		Model:        "gpt-4o",
+		FinishReason: "tool_calls",
+		ToolCalls:    calls,
+		Usage:        provider.Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+	}
+}
+
+func TestTool(t *testing.T) {
+	var calls []string
+	tool := newWeatherTool(t, &calls)
+
+	def := tool.Definition()
+	if def.Name != "get_weather" || def.Description == "" {
+		t.Errorf("unexpected definition: %+v", def)
+	}
+	if !json.Valid(def.Parameters) || !strings.Contains(string(def.Parameters), `"city"`) {
+		t.Errorf("parameters should be the argument schema, got %s", def.Parameters)
+	}
+
+	got, err := tool.Call(context.Background(), `{"city": "Paris"}`)
+	if err != nil {
+		t.Fatalf("Call() error = %v", err)
+	}
+	if got != `{"city":"Paris","temperature":21}` {
+		t.Errorf("Call() = %s", got)
+	}
+
+	if _, err := tool.Call(context.Background(), `{"city": ""}`); err == nil {
+		t.Error("expected error for arguments that fail the schema")
+	}
+	if _, err := tool.Call(context.Background(), `{"city": 1`); err == nil {
+		t.Error("expected error for malformed arguments")
+	}
+	if len(calls) != 1 {
+		t.Errorf("invalid arguments should not reach the function, got calls %v", calls)
+	}
+
+	t.Run("string result", func(t *testing.T) {
+		echo, err := NewTool("echo", "Echo the city", func(_ context.Context, args weatherArgs) (string, error) {
+			return args.City, nil
+		})
+		if err != nil {
+			t.Fatalf("NewTool() error = %v", err)
+		}
+		if got, _ := echo.Call(context.Background(), `{"city": "Oslo"}`); got != "Oslo" {
+			t.Errorf("Call() = %q, want Oslo", got)
+		}
+	})
+
+	t.Run("struct literal", func(t *testing.T) {
+		lit := &Tool[weatherArgs, string]{
+			Name: "echo",
+			Func: func(_ context.Context, args weatherArgs) (string, error) {
+				return args.City, nil
+			},
+		}
+		if def := lit.Definition(); !strings.Contains(string(def.Parameters), `"city"`) {
+			t.Errorf("parameters should be generated on first use, got %s", def.Parameters)
+		}
+		if got, err := lit.Call(context.Background(), `{"city": "Oslo"}`); err != nil || got != "Oslo" {
+			t.Errorf("Call() = %q, %v, want Oslo", got, err)
+		}
+		if _, err := lit.Call(context.Background(), `{"city": ""}`); err == nil {
+			t.Error("expected error for arguments that fail the schema")
+		}
+	})
+
+	t.Run("missing function", func(t *testing.T) {
+		empty := &Tool[weatherArgs, string]{Name: "empty"}
+		if _, err := empty.Call(context.Background(), `{"city": "Oslo"}`); err == nil {
+			t.Error("expected error for a tool without a function")
+		}
+	})
+}
+
+func TestRunWithTools(t *testing.T) {
+	t.Run("call loop", func(t *testing.T) {
+		var calls []string
+		mock := &provider.MockProvider{
+			Responses: []provider.Response{
+				toolCall(
+					provider.ToolCall{ID: "call_1", Name: "get_weather", Arguments: `{"city": "Paris"}`},
+					provider.ToolCall{ID: "call_2", Name: "get_weather", Arguments: `{"city": "Atlantis"}`},
+				),
+				{
+					Content: `{"summary": "21 degrees in Paris"}`,
+					Model:   "gpt-4o",
+					Usage:   provider.Usage{PromptTokens: 30, CompletionTokens: 8, TotalTokens: 38},
+				},
+			},
+		}
+
+		gen, _ := Create[string, weatherAnswer]("What's the weather in {{.}}?")
+		gen.WithProvider(mock).WithTool(newWeatherTool(t, &calls))
+
+		result, err := gen.RunWithMeta(context.Background(), "Paris and Atlantis")
+		if err != nil {
+			t.Fatalf("RunWithMeta() error = %v", err)
+		}
+		if result.Output.Summary != "21 degrees in Paris" {
+			t.Errorf("unexpected output: %+v", result.Output)
+		}
+		if result.Usage.TotalTokens != 53 {
+			t.Errorf("usage should cover every call, got %+v", result.Usage)
+		}
+		if strings.Join(calls, ",") != "Paris,Atlantis" {
+			t.Errorf("unexpected tool calls: %v", calls)
+		}
+
+		if len(mock.Requests) != 2 {
+			t.Fatalf("expected 2 requests, got %d", len(mock.Requests))
+		}
+		if tools := mock.Requests[0].Tools; len(tools) != 1 || tools[0].Name != "get_weather" {
+			t.Errorf("tools should be sent to the provider, got %+v", tools)
+		}
+
+		followUp := mock.Requests[1].Messages
+		if len(followUp) != 4 {
+			t.Fatalf("expected the tool exchange to be appended, got %+v", followUp)
+		}
+		if followUp[1].Role != provider.RoleAssistant || len(followUp[1].ToolCalls) != 2 {
+			t.Errorf("expected the assistant's tool calls, got %+v", followUp[1])
+		}
+		if followUp[2].Role != provider.RoleTool || followUp[2].ToolCallID != "call_1" || followUp[2].Content != `{"city":"Paris","temperature":21}` {
+			t.Errorf("unexpected tool result: %+v", followUp[2])
+		}
+		if followUp[3].ToolCallID != "call_2" || !strings.Contains(followUp[3].Content, "city not found") {
+			t.Errorf("tool errors should be reported to the model, got %+v", followUp[3])
+		}
+	})
+
+	t.Run("unknown tool", func(t *testing.T) {
+		mock := &provider.MockProvider{
+			Response:  `{"summary": "Sorry"}`,
+			Responses: []provider.Response{toolCall(provider.ToolCall{ID: "call_1", Name: "get_time"})},
+		}
+
+		gen, _ := Create[string, weatherAnswer]("What's the time in {{.}}?")
+		gen.WithProvider(mock)
+
+		if _, err := gen.Run(context.Background(), "Paris"); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		if got := mock.Requests[1].Messages[2].Content; !strings.Contains(got, `unknown tool "get_time"`) {
+			t.Errorf("unexpected tool result: %q", got)
+		}
+	})
+
+	t.Run("max rounds", func(t *testing.T) {
+		var calls []string
+		call := provider.ToolCall{ID: "call", Name: "get_weather", Arguments: `{"city": "Paris"}`}
+		mock := &provider.MockProvider{
+			Responses: []provider.Response{toolCall(call), toolCall(call), toolCall(call)},
+		}
+
+		gen, _ := Create[string, weatherAnswer]("What's the weather in {{.}}?")
+		gen.WithProvider(mock).WithTool(newWeatherTool(t, &calls)).WithMaxToolRounds(2)
+
+		_, err := gen.Run(context.Background(), "Paris")
+		var perr *Error
+		if !errors.As(err, &perr) || perr.Code != "tool_rounds_exceeded" {
+			t.Fatalf("expected tool_rounds_exceeded error, got %v", err)
+		}
+		if len(calls) != 2 {
+			t.Errorf("expected 2 tool calls, got %d", len(calls))
+		}
+	})
+
+	t.Run("streams do not send tools", func(t *testing.T) {
+		var calls []string
+		mock := &provider.MockProvider{StreamTokens: []string{`{"summary": "Sunny"}`}}
+
+		gen, _ := Create[string, weatherAnswer]("What's the weather in {{.}}?")
+		gen.WithProvider(mock).WithTool(newWeatherTool(t, &calls))
+
+		stream, err := gen.Stream(context.Background(), "Paris")
+		if err != nil {
+			t.Fatalf("Stream() error = %v", err)
+		}
+		if _, err := stream.Result(); err != nil {
+			t.Fatalf("Result() error = %v", err)
+		}
+		if tools := mock.Requests[0].Tools; tools != nil {
+			t.Errorf("expected no tools, got %+v", tools)
+		}
+	})
+}